@@ -0,0 +1,54 @@
+package ai
+
+// Difficulty is a preset strength for the agent, see SetDifficulty
+type Difficulty int
+
+const (
+	BEGINNER Difficulty = iota
+	NORMAL
+	HARD
+	EXPERT
+)
+
+var DIFFICULTIES = []Difficulty{BEGINNER, NORMAL, HARD, EXPERT}
+
+func (d Difficulty) String() string {
+	if d == BEGINNER {
+		return "Beginner"
+	} else if d == NORMAL {
+		return "Normal"
+	} else if d == HARD {
+		return "Hard"
+	} else if d == EXPERT {
+		return "Expert"
+	}
+	return ""
+}
+
+// DifficultySettings are the parts of the agent that a difficulty changes
+type DifficultySettings struct {
+	Depth int
+	// see Agent.Noise
+	Noise int
+	// see Agent.BlunderChance
+	BlunderChance float64
+}
+
+// Settings of each difficulty. Expert is the agent at full strength
+func (d Difficulty) Settings() DifficultySettings {
+	if d == BEGINNER {
+		return DifficultySettings{Depth: 1, Noise: 6, BlunderChance: 0.25}
+	} else if d == NORMAL {
+		return DifficultySettings{Depth: 1, Noise: 2, BlunderChance: 0.05}
+	} else if d == HARD {
+		return DifficultySettings{Depth: 2}
+	}
+	return DifficultySettings{Depth: DEFAULT_DEPTH}
+}
+
+func (a *Agent) SetDifficulty(d Difficulty) {
+	s := d.Settings()
+	a.Depth = s.Depth
+	a.Noise = s.Noise
+	a.BlunderChance = s.BlunderChance
+}
@@ -0,0 +1,288 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// Feature is something about a position that the evaluation looks at. Each one is measured
+// for EAST minus the same for WEST, and the evaluation adds them up with their Weights
+type Feature int
+
+const (
+	// EvalHealth of the bases, which makes the first points of damage count less than the
+	// last ones
+	HEALTH_FEATURE Feature = iota
+	// power of the creatures on the board, counting shields, armor and experience too.
+	// see creatureValue
+	BOARD_POWER_FEATURE
+	// the same for the creatures that haven't entered the board yet
+	RESERVE_POWER_FEATURE
+	// columns the creatures on the board still have to cross to reach the enemy base,
+	// counted against the side
+	DISTANCE_TO_GOAL_FEATURE
+	// what the creatures will get from the effects of the tiles they step onto next
+	PENDING_BUFFS_FEATURE
+	// power of the creatures that an enemy is about to run into and kill, counted against
+	// the side
+	THREATENED_CREATURES_FEATURE
+	FEATURE_COUNT
+)
+
+// the names of the features in weights files
+var FEATURE_NAMES = [FEATURE_COUNT]string{
+	"health",
+	"board_power",
+	"reserve_power",
+	"distance_to_goal",
+	"pending_buffs",
+	"threatened_creatures",
+}
+
+func (f Feature) String() string {
+	return FEATURE_NAMES[f]
+}
+
+// Weights say how much each feature is worth, indexed by Feature
+type Weights [FEATURE_COUNT]float64
+
+// DEFAULT_WEIGHTS is the evaluation the agent has always used. The newer features are left
+// out until they've been tuned
+var DEFAULT_WEIGHTS = Weights{
+	HEALTH_FEATURE:        1,
+	BOARD_POWER_FEATURE:   1,
+	RESERVE_POWER_FEATURE: 1,
+}
+
+func (w Weights) String() string {
+	s := ""
+	for f := Feature(0); f < FEATURE_COUNT; f++ {
+		if f > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%s=%.3f", f, w[f])
+	}
+	return s
+}
+
+// roughly what a shield saves in a collision
+const SHIELD_VALUE = 3
+
+// a point of armor saves a point of power in a collision, but does no damage to bases
+const ARMOR_VALUE = 1
+
+// experienced creatures are closer to their next level bonus. the bonuses they already have
+// are part of their power
+const EXPERIENCE_VALUE = 1
+
+func EvalHealth(h int) int {
+	return int(math.Sqrt(float64(h * 100)))
+}
+
+// Evaluate scores the game for the side whose turn it is. Wins found sooner are worth more
+func (w Weights) Evaluate(c *core.Game, depth int) int {
+	multiplier := 1
+	if c.CurrentTurn == core.WEST {
+		multiplier = -1
+	}
+
+	if c.IsOver() {
+		if c.Winner == core.WEST {
+			return multiplier * -(10000 - depth)
+		} else if c.Winner == core.EAST {
+			return multiplier * (10000 - depth)
+		}
+		return 0
+	}
+
+	value := 0.0
+	for f := Feature(0); f < FEATURE_COUNT; f++ {
+		// most features are off, and they aren't free to measure
+		if w[f] != 0 {
+			value += w[f] * float64(f.Measure(c))
+		}
+	}
+	return multiplier * int(math.Round(value))
+}
+
+// Measure is the value of the feature for EAST minus its value for WEST
+func (f Feature) Measure(c *core.Game) int {
+	if f == HEALTH_FEATURE {
+		return EvalHealth(c.EastHealth) - EvalHealth(c.WestHealth)
+	} else if f == THREATENED_CREATURES_FEATURE {
+		return threatenedPower(c, core.WEST, core.EAST) - threatenedPower(c, core.EAST, core.WEST)
+	}
+
+	value := 0
+	for _, side := range []core.Alignment{core.EAST, core.WEST} {
+		sideValue := 0
+		for _, cr := range c.Creatures(side) {
+			if cr.Removed {
+				continue
+			}
+			onBoard := !core.IsOffMap(cr.X)
+			if f == BOARD_POWER_FEATURE && onBoard {
+				sideValue += creatureValue(cr)
+			} else if f == RESERVE_POWER_FEATURE && !onBoard {
+				sideValue += creatureValue(cr)
+			} else if f == DISTANCE_TO_GOAL_FEATURE && onBoard {
+				sideValue -= columnsToGo(cr)
+			} else if f == PENDING_BUFFS_FEATURE {
+				sideValue += pendingBuffs(c, cr)
+			}
+		}
+		value += int(side) * sideValue
+	}
+
+	// creatures on the board now will reach the enemy base in about this many turns, so
+	// they'll deal more damage if sudden death has started by then
+	if f == BOARD_POWER_FEATURE || f == RESERVE_POWER_FEATURE {
+		value *= c.DamageMultiplier(c.TurnNumber + 2*core.MAP_WIDTH)
+	}
+	return value
+}
+
+// creatureValue is what the creature is worth in collisions
+func creatureValue(c *core.Creature) int {
+	value := c.Power + c.Armor*ARMOR_VALUE + c.Experience*EXPERIENCE_VALUE
+	if c.Shielded {
+		value += SHIELD_VALUE
+	}
+	return value
+}
+
+func columnsToGo(c *core.Creature) int {
+	if c.Alignment == core.EAST {
+		return core.MAP_WIDTH - c.X
+	}
+	return c.X + 1
+}
+
+// nextTile is the tile the creature steps onto when its side moves next, or nil if it
+// leaves the board or is still waiting in the reserves behind another creature
+func nextTile(g *core.Game, c *core.Creature) *core.Tile {
+	x := c.X + int(c.Alignment)
+	y := c.Y
+	// creatures coming onto the board go straight in
+	if !core.IsOffMap(c.X) {
+		if g.Map.Tiles[c.X][c.Y].Reversed {
+			y += 1
+		} else {
+			y -= 1
+		}
+	}
+	return g.Map.Tile(x, y)
+}
+
+// pendingBuffs is what the effect of the creature's next tile would give its side
+func pendingBuffs(g *core.Game, c *core.Creature) int {
+	t := nextTile(g, c)
+	if t == nil {
+		return 0
+	}
+	e := t.GetActiveEffect()
+	value := 0
+	for _, ally := range g.Creatures(c.Alignment) {
+		if ally.Removed || (core.IsOffMap(ally.X) && ally != c) {
+			continue
+		}
+		if e.Targets == core.TILE && ally != c {
+			continue
+		}
+		if e.ColorCondition != core.NO_COLOR && e.ColorCondition != ally.Color {
+			continue
+		}
+		if e.SpeciesCondition != core.NO_SPECIES && e.SpeciesCondition != ally.Species {
+			continue
+		}
+		if e.Kind == core.ARMOR_EFFECT {
+			value += e.Value * ARMOR_VALUE
+		} else if e.Kind == core.SHIELD_EFFECT {
+			if !ally.Shielded {
+				value += SHIELD_VALUE
+			}
+		} else {
+			value += e.Value
+		}
+	}
+	return value
+}
+
+// threatenedPower adds up the power of the creatures of the side that an enemy will run
+// into when the attacker moves next and that wouldn't survive the collision
+func threatenedPower(g *core.Game, attacker, side core.Alignment) int {
+	value := 0
+	for _, c := range g.Creatures(side) {
+		if c.Removed || core.IsOffMap(c.X) || c.Shielded {
+			continue
+		}
+		for _, enemy := range g.Creatures(attacker) {
+			if enemy.Removed || enemy.Power < c.Power+c.Armor {
+				continue
+			}
+			if t := nextTile(g, enemy); t != nil && t.X == c.X && t.Y == c.Y {
+				value += c.Power
+				break
+			}
+		}
+	}
+	return value
+}
+
+// ReadWeights reads weights saved by Write. Features that aren't in the file keep their
+// default weight
+func ReadWeights(r io.Reader) (Weights, error) {
+	var w Weights
+	err := json.NewDecoder(r).Decode(&w)
+	return w, err
+}
+
+func LoadWeights(path string) (Weights, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Weights{}, err
+	}
+	defer f.Close()
+	return ReadWeights(f)
+}
+
+// Write saves the weights as a JSON object from feature names to weights
+func (w Weights) Write(wr io.Writer) error {
+	enc := json.NewEncoder(wr)
+	enc.SetIndent("", "  ")
+	return enc.Encode(w)
+}
+
+func (w Weights) MarshalJSON() ([]byte, error) {
+	named := make(map[string]float64)
+	for f := Feature(0); f < FEATURE_COUNT; f++ {
+		named[FEATURE_NAMES[f]] = w[f]
+	}
+	return json.Marshal(named)
+}
+
+func (w *Weights) UnmarshalJSON(b []byte) error {
+	named := make(map[string]float64)
+	if err := json.Unmarshal(b, &named); err != nil {
+		return err
+	}
+	*w = DEFAULT_WEIGHTS
+	for name, value := range named {
+		found := false
+		for f := Feature(0); f < FEATURE_COUNT; f++ {
+			if FEATURE_NAMES[f] == name {
+				w[f] = value
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown feature %q", name)
+		}
+	}
+	return nil
+}
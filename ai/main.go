@@ -1,8 +1,13 @@
 package ai
 
 import (
-	"math"
+	"context"
 	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/prizelobby/reverset-raiders/core"
 )
@@ -13,54 +18,216 @@ type Agent struct {
 	// note: this should probably be tracked in the game but it is too much
 	// work to do right now
 	TurnsTaken int
+	// the most plies searched after each of the agent's own moves. the search deepens one
+	// ply at a time until it gets here or runs out of budget
+	Depth int
+	// the search gives up and plays the best move found so far once this passes.
+	// the zero value means there is no time limit
+	Deadline time.Time
+	// time allowed for each move on top of the deadline, zero for no limit
+	MoveTime time.Duration
+	// nodes searched for each move before giving up, zero for no limit
+	MaxNodes int
+	// positions searched so far, kept between moves. nil turns the table off
+	Table *TranspositionTable
+	// the game the entries of the tables come from, see checkTables
+	tableSeed    int64
+	tableRules   core.Rules
+	tableWeights Weights
+	// goroutines that search the root moves at the same time. zero or one searches
+	// without starting any
+	Workers int
+	// searches the moves most likely to be best first, see orderMoves. Turning it off
+	// only puts the move from the table first
+	Ordering bool
+	// each root move's value is off by up to this much, so weaker agents misjudge moves
+	// that are close. see SetDifficulty
+	Noise int
+	// the chance of playing a random move instead of searching at all
+	BlunderChance float64
+	// how the positions at the end of the search are scored
+	Weights Weights
+	Stats   SearchStats
+	workers []*Agent
+	// moves that caused cutoffs, for each depth, and how often each tile was part of one
+	// for each side. see cutoff
+	killers  [][KILLERS_PER_DEPTH]core.GameMove
+	history  [2][core.MAP_WIDTH][2 * core.MAP_HEIGHT]int
+	orderBuf [][]scoredMove
+	// the search also stops when this is done, see ChooseMove
+	ctx     context.Context
+	stopAt  time.Time
+	nodes   int
+	stopped bool
 }
 
+// SearchStats describes the agent's last search
+type SearchStats struct {
+	Nodes int
+	// the deepest search that got through at least one move
+	Depth int
+	// positions whose moves were searched, the moves searched from them, and how many
+	// of those searches ended early because a move was good enough
+	Expanded int
+	Children int
+	Cutoffs  int
+}
+
+// BranchingFactor is the average number of moves searched from each position. The better
+// the moves are ordered, the sooner a cutoff comes and the lower it is
+func (s SearchStats) BranchingFactor() float64 {
+	if s.Expanded == 0 {
+		return 0
+	}
+	return float64(s.Children) / float64(s.Expanded)
+}
+
+func (s *SearchStats) add(o SearchStats) {
+	s.Nodes += o.Nodes
+	s.Expanded += o.Expanded
+	s.Children += o.Children
+	s.Cutoffs += o.Cutoffs
+}
+
+const DEFAULT_DEPTH = 4
+
+// how deep an agent with a time or node budget may go, see Agent.Depth
+const MAX_SEARCH_DEPTH = 12
+
 func NewAgent(GameSeed int64, RandomSeed int64) *Agent {
+	return NewAgentWithRules(core.DefaultRules(), GameSeed, RandomSeed)
+}
+
+func NewAgentWithRules(rules core.Rules, GameSeed int64, RandomSeed int64) *Agent {
 	s := rand.NewSource(RandomSeed)
 	random := rand.New(s)
 
-	return &Agent{Game: core.NewGameWithSeed(GameSeed), Random: random, TurnsTaken: 0}
+	return &Agent{
+		Game:       core.NewGameWithRules(GameSeed, rules),
+		Random:     random,
+		TurnsTaken: 0,
+		Depth:      DEFAULT_DEPTH,
+		Table:      NewTranspositionTable(DEFAULT_TABLE_SIZE),
+		Ordering:   true,
+		Weights:    DEFAULT_WEIGHTS,
+	}
 }
 
 func (a *Agent) Reset() {
 
 }
 
+type rootMove struct {
+	Move core.GameMove
+	// the value from the last depth searched, or a bound on it if the move wasn't the best
+	Score int
+	// where the move was before sorting, which breaks ties
+	Order int
+	// added to the value of the move, see Agent.Noise
+	Noise int
+}
+
+// rootBest is the best root move found so far, shared by the workers. Ties go to the move
+// with the lowest Order, so that the choice doesn't depend on which worker got there first
+type rootBest struct {
+	mu    sync.Mutex
+	index int
+	order int
+	value int
+}
+
+// alphaFor is the window to search a move with. A move that comes before the best one
+// only needs to tie with it, so its window starts one lower
+func (b *rootBest) alphaFor(m rootMove) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.index != -1 && m.Order < b.order {
+		return b.value - m.Noise - 1
+	}
+	return b.value - m.Noise
+}
+
+func (b *rootBest) offer(i int, m rootMove, value int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.index == -1 || value > b.value || (value == b.value && m.Order < b.order) {
+		b.index = i
+		b.order = m.Order
+		b.value = value
+	}
+}
+
 func (a *Agent) MakeMove() (core.GameMove, []core.GameEvent) {
 	//start := time.Now()
-
-	moves := a.Game.GenerateLegalMoves()
+	a.startSearch()
+
+	legal := a.Game.GenerateLegalMoves()
+	// every tile is taken or frozen, which can happen on the smaller boards, so the turn
+	// is passed
+	if len(legal) == 0 {
+		a.TurnsTaken += 1
+		return core.GameMove{}, a.Game.AcceptMove(core.GameMove{})
+	}
+	if a.BlunderChance > 0 && a.Random.Float64() < a.BlunderChance {
+		move := legal[a.Random.Intn(len(legal))]
+		a.TurnsTaken += 1
+		return move, a.Game.AcceptMove(move)
+	}
 
 	// start at an offset so that if all the evaluations are the same, we choose
 	// a random move instead of the first move in the array
-	randomOffset := a.Random.Intn(len(moves))
-	move := moves[randomOffset]
-	best := -10000
-	for i := 0; i < len(moves); i++ {
-		m := moves[(i+randomOffset)%len(moves)]
-
-		e := a.Game.AcceptMove(m)
-
-		var val int
-		// try making the earlier turns take less time
-		if a.TurnsTaken < 2 {
-			val = -a.SemiNegaMax(4, -10000, 10000)
-		} else {
-			val = -a.NegaMax(4, -10000, 10000)
+	randomOffset := a.Random.Intn(len(legal))
+	moves := make([]rootMove, len(legal))
+	for i := range legal {
+		moves[i].Move = legal[(i+randomOffset)%len(legal)]
+		moves[i].Order = i
+		if a.Noise > 0 {
+			moves[i].Noise = a.Random.Intn(2*a.Noise+1) - a.Noise
 		}
+	}
+	if a.Workers > 1 {
+		a.prepareWorkers()
+	}
 
-		//too slow
-		//val := -a.GuidedNegaMax(m, 4, -10000, 10000)
-
-		//too slow
-		//val := -a.SemiNegaMax(5, -10000, 10000)
+	move := moves[0].Move
+	best := -10000
+	depth := 0
+	noise := 0
+	for d := 0; d <= a.Depth; d++ {
+		i, val := a.searchRoot(moves, d)
+		// a search that was cut short still counts if it got through a move. the best move
+		// of the last depth is searched first, so anything that beats it is better
+		if i != -1 {
+			move, best, depth, noise = moves[i].Move, val, d, moves[i].Noise
+		}
+		// a win can't get any better
+		if a.stopped || best >= 10000-MAX_SEARCH_DEPTH {
+			break
+		}
+		// the next depth searches the best moves of this one first, which lets the alpha
+		// beta search prune much more
+		sort.SliceStable(moves, func(i, j int) bool {
+			return moves[i].Score > moves[j].Score
+		})
+	}
 
-		//fmt.Printf("value %d, current best %d\n", val, best)
-		if val > best {
-			move = m
-			best = val
+	// reserve swaps and powers are only tried along with the best reversals found, since
+	// adding them to every move makes the search far too slow. they go with the same
+	// reversals, so they get the same noise
+	best -= noise
+	if !a.stopped {
+		prefix := core.NewGameMove(move.Coords...)
+		if len(prefix.Coords) >= a.Game.ReversalsAllowed(a.Game.CurrentTurn) {
+			prefix.Coords = prefix.Coords[:len(prefix.Coords)-1]
 		}
-		a.ReverseEvents(e)
+		move, best = a.bestOf(a.Game.GenerateSwapMoves(prefix), move, best, depth)
+	}
+	if !a.stopped {
+		powerMoves := make([]core.GameMove, 0)
+		for _, p := range a.Game.GeneratePowerMoves() {
+			powerMoves = append(powerMoves, move.WithPower(p))
+		}
+		move, _ = a.bestOf(powerMoves, move, best, depth)
 	}
 
 	e := a.Game.AcceptMove(move)
@@ -68,225 +235,425 @@ func (a *Agent) MakeMove() (core.GameMove, []core.GameEvent) {
 	//duration := time.Since(start)
 	//fmt.Println(duration)
 
+	a.Stats.Nodes = a.nodes
+	a.Stats.Depth = depth
+	if a.Workers > 1 {
+		for _, w := range a.workers[:a.Workers] {
+			w.Stats.Nodes = w.nodes
+			a.Stats.add(w.Stats)
+		}
+	}
 	a.TurnsTaken += 1
 	return move, e
 }
 
-func (a *Agent) ReverseEvents(events []core.GameEvent) {
-	for i := len(events) - 1; i >= 0; i-- {
-		e := events[i]
-		if e.EventType == core.WARP {
-			e.SourceCreature.X = e.SourceX
-			e.SourceCreature.Y = e.SourceY
-			if e.TargetX >= 0 && e.TargetX < core.MAP_WIDTH {
-				a.Game.Map.Tiles[e.TargetX][e.TargetY].HasCreature = false
-			}
-		} else if e.EventType == core.MOVE {
-			e.SourceCreature.X = e.SourceX
-			e.SourceCreature.Y = e.SourceY
-			if e.SourceX >= 0 && e.SourceX < core.MAP_WIDTH {
-				a.Game.Map.Tiles[e.SourceX][e.SourceY].HasCreature = true
-				if e.SourceCreature.Removed {
-					e.SourceCreature.Removed = false
+// searchRoot searches the moves in order to the given depth and returns the index of the
+// best one, or -1 if the search was stopped before it got through any of them
+func (a *Agent) searchRoot(moves []rootMove, depth int) (int, int) {
+	best := &rootBest{index: -1, value: -10000}
+	if a.Workers > 1 {
+		a.parallelSearchRoot(moves, depth, best)
+		return best.index, best.value
+	}
+
+	for i := range moves {
+		val := a.rootValue(moves[i].Move, depth, best.alphaFor(moves[i])) + moves[i].Noise
+		// the value of a move whose search was cut short can't be trusted
+		if a.stopped {
+			break
+		}
+
+		moves[i].Score = val
+		//fmt.Printf("value %d, current best %d\n", val, best)
+		best.offer(i, moves[i], val)
+	}
+	return best.index, best.value
+}
+
+// parallelSearchRoot hands out the moves to the workers in order. Each worker searches
+// its own copy of the game, and they share the best value so far as their alpha
+func (a *Agent) parallelSearchRoot(moves []rootMove, depth int, best *rootBest) {
+	var next atomic.Int64
+	var stopped atomic.Bool
+	var wg sync.WaitGroup
+	for _, w := range a.workers[:a.Workers] {
+		w := w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stopped.Load() {
+				i := int(next.Add(1) - 1)
+				if i >= len(moves) {
+					return
 				}
-			}
-			if e.TargetX >= 0 && e.TargetX < core.MAP_WIDTH {
-				if e.TargetY >= e.TargetX%2 && e.TargetY < 2*core.MAP_HEIGHT-1+(e.TargetX%2) {
-					a.Game.Map.Tiles[e.TargetX][e.TargetY].HasCreature = false
+				val := w.rootValue(moves[i].Move, depth, best.alphaFor(moves[i])) + moves[i].Noise
+				if w.stopped {
+					stopped.Store(true)
+					return
 				}
+				moves[i].Score = val
+				best.offer(i, moves[i], val)
 			}
-		} else if e.EventType == core.DEAL_DAMAGE {
-			if e.TargetX == int(core.WEST) {
-				a.Game.WestHealth += e.Value
-			} else if e.TargetX == int(core.EAST) {
-				a.Game.EastHealth += e.Value
-			}
-		} else if e.EventType == core.UPDATE_POWER {
-			e.TargetCreature.Power -= e.Value
-		} else if e.EventType == core.DEATH {
-			e.SourceCreature.X = e.SourceX
-			e.SourceCreature.Y = e.SourceY
-			e.SourceCreature.Removed = false
-			a.Game.Map.Tiles[e.SourceX][e.SourceY].HasCreature = true
-		} else if e.EventType == core.REVERSE_TILE {
-			a.Game.Map.Tiles[e.SourceX][e.SourceY].Reversed = !a.Game.Map.Tiles[e.SourceX][e.SourceY].Reversed
+		}()
+	}
+	wg.Wait()
+	a.stopped = stopped.Load()
+}
+
+// prepareWorkers gives every worker a copy of the position and its share of the budget.
+// The workers keep their tables between moves
+func (a *Agent) prepareWorkers() {
+	for len(a.workers) < a.Workers {
+		w := &Agent{}
+		if a.Table != nil {
+			w.Table = NewTranspositionTable(a.Table.Size)
 		}
+		a.workers = append(a.workers, w)
+	}
+	for _, w := range a.workers[:a.Workers] {
+		w.Game = a.Game.Clone()
+		w.TurnsTaken = a.TurnsTaken
+		w.Ordering = a.Ordering
+		w.Weights = a.Weights
+		w.Stats = SearchStats{}
+		w.ageHistory()
+		w.ctx = a.ctx
+		w.stopAt = a.stopAt
+		w.MaxNodes = 0
+		if a.MaxNodes > 0 {
+			w.MaxNodes = a.MaxNodes/a.Workers + 1
+		}
+		w.nodes = 0
+		w.stopped = false
 	}
-	a.Game.CurrentTurn = a.Game.CurrentTurn.Opposite()
 }
 
-func (a *Agent) AcceptMove(move core.GameMove) []core.GameEvent {
-	return a.Game.AcceptMove(move)
+// bestOf searches the moves and returns the best one if it beats the best move so far
+func (a *Agent) bestOf(moves []core.GameMove, move core.GameMove, best int, depth int) (core.GameMove, int) {
+	for _, m := range moves {
+		val := a.rootValue(m, depth, best)
+		if a.stopped {
+			break
+		}
+		if val > best {
+			move = m
+			best = val
+		}
+	}
+	return move, best
 }
 
-func EvalHealth(h int) int {
-	return int(math.Sqrt(float64(h * 100)))
+// rootValue searches the position after one of the agent's own moves. Moves that aren't
+// better than alpha only get an upper bound on their value
+func (a *Agent) rootValue(m core.GameMove, depth int, alpha int) int {
+	e := a.Game.AcceptMove(m)
+	defer a.ReverseEvents(e)
+
+	// try making the earlier turns take less time. searching every full move for the
+	// opponent also gets too slow when they can reverse more than two tiles
+	if a.TurnsTaken < 2 || a.Game.ReversalsAllowed(a.Game.CurrentTurn) > core.REVERSALS_PER_TURN {
+		return -a.SemiNegaMax(depth, -10000, -alpha)
+	}
+	return -a.NegaMax(depth, -10000, -alpha)
+
+	//too slow
+	//val := -a.GuidedNegaMax(m, 4, -10000, 10000)
+
+	//too slow
+	//val := -a.SemiNegaMax(5, -10000, 10000)
 }
 
-func (a *Agent) evaluation(c *core.Game, depth int) int {
-	multiplier := 1
-	if c.CurrentTurn == core.WEST {
-		multiplier = -1
+// DeadlineFor picks how long the agent playing the given side may think, leaving some room
+// in the budget for later moves. It returns the zero time if there is no clock.
+func DeadlineFor(c *core.Clock, side core.Alignment, now time.Time) time.Time {
+	if c == nil || c.Control.IsUntimed() {
+		return time.Time{}
 	}
+	budget := c.MoveTimeLeft(side, now)
+	if c.Control.Total > 0 {
+		share := c.TotalLeft(side, now)/20 + c.Control.Increment
+		if share < budget {
+			budget = share
+		}
+	}
+	// keep a safety margin so that the move arrives before the flag falls
+	budget -= budget / 10
+	return now.Add(budget)
+}
 
-	if c.EastHealth <= 0 {
-		return multiplier * -(10000 - depth)
-	} else if c.WestHealth <= 0 {
-		return multiplier * (10000 - depth)
+func (a *Agent) outOfTime() bool {
+	if a.stopped {
+		return true
+	}
+	a.nodes += 1
+	if a.MaxNodes > 0 && a.nodes >= a.MaxNodes {
+		a.stopped = true
+		return true
+	}
+	if a.nodes%256 != 0 {
+		return false
+	}
+	if a.ctx != nil && a.ctx.Err() != nil {
+		a.stopped = true
+	} else if !a.stopAt.IsZero() {
+		a.stopped = time.Now().After(a.stopAt)
 	}
+	return a.stopped
+}
 
-	value := 0
-	for _, ec := range a.Game.EastCreatures {
-		if !ec.Removed {
-			value += ec.Power
+// startSearch resets the budget for a new move
+func (a *Agent) startSearch() {
+	a.checkTables()
+	a.nodes = 0
+	a.stopped = false
+	a.Stats = SearchStats{}
+	a.ageHistory()
+	a.stopAt = a.Deadline
+	if a.MoveTime > 0 {
+		if t := time.Now().Add(a.MoveTime); a.stopAt.IsZero() || t.Before(a.stopAt) {
+			a.stopAt = t
 		}
 	}
-	for _, wc := range a.Game.WestCreatures {
-		if !wc.Removed {
-			value -= wc.Power
+	if a.ctx != nil {
+		if t, ok := a.ctx.Deadline(); ok && (a.stopAt.IsZero() || t.Before(a.stopAt)) {
+			a.stopAt = t
 		}
 	}
+}
+
+// checkTables clears the tables when the agent has moved on to another game. Hashes don't
+// tell games with different seeds or rules apart, and the values depend on the weights
+func (a *Agent) checkTables() {
+	if a.Game.Seed == a.tableSeed && a.Weights == a.tableWeights && reflect.DeepEqual(a.Game.Rules, a.tableRules) {
+		return
+	}
+	a.tableSeed, a.tableRules, a.tableWeights = a.Game.Seed, a.Game.Rules, a.Weights
+	a.Table.Clear()
+	for _, w := range a.workers {
+		w.Table.Clear()
+	}
+}
+
+// ChooseMove lets the agent play as a core.Player. The agent searches its own copy of the
+// game and plays the best move found so far if the context is done before it finishes.
+func (a *Agent) ChooseMove(ctx context.Context, view core.GameView) (core.GameMove, error) {
+	a.Game = view.Game()
+	a.ctx = ctx
+	defer func() { a.ctx = nil }()
+
+	if a.Game.Rules.Simultaneous {
+		return a.MakeSimultaneousMove(view.Side), nil
+	}
+	move, _ := a.MakeMove()
+	return move, nil
+}
 
-	return multiplier * (EvalHealth(c.EastHealth) - EvalHealth(c.WestHealth) + value)
+func (a *Agent) ReverseEvents(events []core.GameEvent) {
+	a.Game.UndoEvents(events)
+}
+
+func (a *Agent) AcceptMove(move core.GameMove) []core.GameEvent {
+	return a.Game.AcceptMove(move)
+}
+
+func (a *Agent) evaluation(c *core.Game, depth int) int {
+	return a.Weights.Evaluate(c, depth)
 }
 
 func (a *Agent) PartMoveNegaMax(depth int, alpha, beta int) int {
-	if depth <= 0 || a.Game.WestHealth <= 0 || a.Game.EastHealth <= 0 {
+	if a.outOfTime() {
+		return 0
+	}
+	if depth <= 0 || a.Game.IsOver() {
 		return a.evaluation(a.Game, depth)
 	}
-	coords := a.Game.AllCoords
+	hash := a.Game.Hash() ^ PART_MOVE_SEARCH
+	entry, found := a.Table.Probe(hash)
+	if found && entry.Cutoff(depth, alpha, beta) {
+		return entry.Value
+	}
+	// the best move from the table is searched first
+	first := entry.Move
 
+	alphaOrig := alpha
 	best := -99999
-	for _, coord := range coords {
-		move := core.GameMove{First: coord, Second: core.MapCoord{X: -1, Y: -1}}
-		if !a.Game.IsMoveLocationsEmpty(move) {
-			continue
-		}
+	var bestMove core.GameMove
+	a.Stats.Expanded += 1
+	for _, m := range a.orderMoves(a.Game.SingleMoves, first, depth) {
+		move := m.Move
+		a.Stats.Children += 1
 
 		e := a.Game.AcceptMove(move)
 
 		val := -a.PartMoveNegaMax(depth-1, -beta, -alpha)
 		if val > best {
 			best = val
+			bestMove = move
 		}
 
 		if val > alpha {
 			alpha = val
 		}
 
+		a.ReverseEvents(e)
 		if alpha >= beta {
-			a.ReverseEvents(e)
+			a.cutoff(move, depth)
 			break
 		}
-		a.ReverseEvents(e)
 	}
+	a.store(hash, depth, best, alphaOrig, beta, bestMove)
 	return best
 }
 
 func (a *Agent) NegaMax(depth int, alpha, beta int) int {
-	if depth <= 0 || a.Game.WestHealth <= 0 || a.Game.EastHealth <= 0 {
+	if a.outOfTime() {
+		return 0
+	}
+	if depth <= 0 || a.Game.IsOver() {
 		return a.evaluation(a.Game, depth)
 	}
-	moves := a.Game.AllUncheckedMoves
+	hash := a.Game.Hash() ^ FULL_MOVE_SEARCH
+	entry, found := a.Table.Probe(hash)
+	if found && entry.Cutoff(depth, alpha, beta) {
+		return entry.Value
+	}
+	// the best move from the table is searched first
+	first := entry.Move
+
+	alphaOrig := alpha
+	moves := a.Game.AllUncheckedMovesFor(a.Game.CurrentTurn)
 	best := -99999
-	for _, move := range moves {
-		if !a.Game.IsMoveLocationsEmpty(move) {
-			continue
-		}
+	var bestMove core.GameMove
+	a.Stats.Expanded += 1
+	for _, m := range a.orderMoves(moves, first, depth) {
+		move := m.Move
+		a.Stats.Children += 1
 		e := a.Game.AcceptMove(move)
 		val := -a.PartMoveNegaMax(depth-1, -beta, -alpha)
 		if val > best {
 			best = val
+			bestMove = move
 		}
 		if val > alpha {
 			alpha = val
 		}
+		a.ReverseEvents(e)
 		if alpha >= beta {
-			a.ReverseEvents(e)
+			a.cutoff(move, depth)
 			break
 		}
-		a.ReverseEvents(e)
 	}
+	a.store(hash, depth, best, alphaOrig, beta, bestMove)
 	return best
 }
 
-// this function name isn't really accurate
+// this function name isn't really accurate. the move is built up one reversal at a time,
+// keeping the best reversal found at each step
 func (a *Agent) SemiNegaMax(depth int, alpha, beta int) int {
-	if depth <= 0 || a.Game.WestHealth <= 0 || a.Game.EastHealth <= 0 {
+	if a.outOfTime() {
+		return 0
+	}
+	if depth <= 0 || a.Game.IsOver() {
 		return a.evaluation(a.Game, depth)
 	}
-	coords := a.Game.AllCoords
+	hash := a.Game.Hash() ^ SEMI_SEARCH
+	entry, found := a.Table.Probe(hash)
+	if found && entry.Cutoff(depth, alpha, beta) {
+		return entry.Value
+	}
 
+	alphaOrig := alpha
 	best := -99999
-	var bestPartMove core.GameMove
-	for _, coord := range coords {
-		move := core.GameMove{First: coord, Second: core.MapCoord{X: -1, Y: -1}}
-		if !a.Game.IsMoveLocationsEmpty(move) {
-			continue
-		}
-		e := a.Game.AcceptMove(move)
-		val := -a.PartMoveNegaMax(depth-1, -beta, -alpha)
-		if val > best {
-			best = val
-			bestPartMove = move
+	var bestMove core.GameMove
+	var partMove core.GameMove
+	for step := 0; step < a.Game.ReversalsAllowed(a.Game.CurrentTurn); step++ {
+		stepBest := -99999
+		var bestPartMove core.GameMove
+		// the reversal the table chose at this step is tried first
+		var first core.GameMove
+		if found && step < len(entry.Move.Coords) {
+			first = partMove.With(entry.Move.Coords[step])
 		}
-		if val > alpha {
-			alpha = val
+		moves := make([]core.GameMove, 0, len(a.Game.AllCoords))
+		for _, coord := range a.Game.AllCoords {
+			if !partMove.Contains(coord) {
+				moves = append(moves, partMove.With(coord))
+			}
 		}
-		if alpha >= beta {
+		a.Stats.Expanded += 1
+		for _, m := range a.orderMoves(moves, first, depth) {
+			move := m.Move
+			a.Stats.Children += 1
+			e := a.Game.AcceptMove(move)
+			val := -a.PartMoveNegaMax(depth-1, -beta, -alpha)
+			if val > stepBest {
+				stepBest = val
+				bestPartMove = move
+			}
+			if val > alpha {
+				alpha = val
+			}
 			a.ReverseEvents(e)
-			break
+			if alpha >= beta {
+				a.cutoff(move, depth)
+				break
+			}
 		}
-		a.ReverseEvents(e)
-	}
 
-	best = -99999
-	for _, coord := range coords {
-		if bestPartMove.First.X == coord.X && bestPartMove.First.Y == coord.Y {
-			continue
-		}
-		move := core.GameMove{First: bestPartMove.First, Second: coord}
-		if !a.Game.IsMoveLocationsEmpty(move) {
-			continue
-		}
-		e := a.Game.AcceptMove(move)
-		val := -a.PartMoveNegaMax(depth-1, -beta, -alpha)
-		if val > best {
-			best = val
+		if stepBest > best {
+			best = stepBest
+			bestMove = bestPartMove
 		}
-		if val > alpha {
-			alpha = val
-		}
-		if alpha >= beta {
-			a.ReverseEvents(e)
+		if alpha >= beta || len(bestPartMove.Coords) == 0 {
 			break
 		}
-		a.ReverseEvents(e)
+		partMove = bestPartMove
 	}
 
+	a.store(hash, depth, best, alphaOrig, beta, bestMove)
 	return best
 }
 
 func (a *Agent) GuidedNegaMax(givenMove core.GameMove, depth int, alpha, beta int) int {
-	if depth <= 0 || a.Game.WestHealth <= 0 || a.Game.EastHealth <= 0 {
+	if a.outOfTime() {
+		return 0
+	}
+	if depth <= 0 || a.Game.IsOver() {
 		return a.evaluation(a.Game, depth)
 	}
+	// the moves searched depend on the given move too
+	hash := a.Game.Hash() ^ GUIDED_SEARCH
+	for _, c := range givenMove.Coords {
+		hash = hash*31 + uint64(c.X*2*core.MAP_HEIGHT+c.Y)
+	}
+	entry, found := a.Table.Probe(hash)
+	if found && entry.Cutoff(depth, alpha, beta) {
+		return entry.Value
+	}
+	// the best move from the table is searched first
+	first := entry.Move
 
 	// theoretically, the best move for the opponent will involve reversing the given move
 	// so we should be able to prune off more of the tree
-	moves := a.Game.GenerateNextSteps(givenMove.First)
-	moves = append(moves, a.Game.GenerateNextSteps(givenMove.Second)...)
+	moves := make([]core.GameMove, 0)
+	for _, c := range givenMove.Coords {
+		moves = append(moves, a.Game.GenerateNextSteps(core.NewGameMove(c))...)
+	}
 	moves = append(moves, a.Game.GenerateLegalMovesWithExclusions(givenMove)...)
 
+	alphaOrig := alpha
 	best := -99999
-	for _, move := range moves {
-		if !a.Game.IsMoveLocationsEmpty(move) {
+	var bestMove core.GameMove
+	for i := -1; i < len(moves); i++ {
+		move, ok := nthMove(moves, i, first)
+		if !ok || !a.Game.IsMoveLocationsEmpty(move) {
 			continue
 		}
 		e := a.Game.AcceptMove(move)
 		val := -a.GuidedNegaMax(move, depth-1, -beta, -alpha)
 		if val > best {
 			best = val
+			bestMove = move
 		}
 		if val > alpha {
 			alpha = val
@@ -297,5 +664,21 @@ func (a *Agent) GuidedNegaMax(givenMove core.GameMove, depth int, alpha, beta in
 		}
 		a.ReverseEvents(e)
 	}
+	a.store(hash, depth, best, alphaOrig, beta, bestMove)
 	return best
 }
+
+// store saves the result of a search in the table. Searches that were cut short are left
+// out since their values can't be trusted
+func (a *Agent) store(hash uint64, depth int, value int, alpha, beta int, move core.GameMove) {
+	if a.stopped {
+		return
+	}
+	a.Table.Store(TableEntry{
+		Hash:  hash,
+		Depth: depth,
+		Value: value,
+		Bound: boundFor(value, alpha, beta),
+		Move:  move,
+	})
+}
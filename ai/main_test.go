@@ -1,10 +1,14 @@
 package ai_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/prizelobby/reverset-raiders/ai"
 	"github.com/prizelobby/reverset-raiders/core"
@@ -48,6 +52,717 @@ func TestReverseGame2(t *testing.T) {
 	}
 }
 
+func TestReversalsPerTurn(t *testing.T) {
+	choose := func(n, k int) int {
+		c := 1
+		for i := 0; i < k; i++ {
+			c = c * (n - i) / (i + 1)
+		}
+		return c
+	}
+	for _, reversals := range []int{1, 3} {
+		rules := core.DefaultRules()
+		rules.ReversalsPerTurn = reversals
+		game := core.NewGameWithRules(6, rules)
+		if game.ReversalsAllowed(core.EAST) != reversals {
+			t.Fatalf("%d reversals allowed, expected %d", game.ReversalsAllowed(core.EAST), reversals)
+		}
+
+		// every set of up to the allowed number of empty tiles, each one once
+		expected := 0
+		for k := 1; k <= reversals; k++ {
+			expected += choose(len(game.EmptyCoords()), k)
+		}
+		moves := game.GenerateLegalMoves()
+		if len(moves) != expected {
+			t.Fatalf("%d reversals: %d moves, expected %d", reversals, len(moves), expected)
+		}
+		seen := make(map[string]bool)
+		for _, m := range moves {
+			if len(m.Coords) == 0 || len(m.Coords) > reversals {
+				t.Fatalf("%d reversals: move %v has the wrong size", reversals, m.Coords)
+			}
+			key := fmt.Sprint(m.Coords)
+			if seen[key] {
+				t.Fatalf("%d reversals: move %v is generated twice", reversals, m.Coords)
+			}
+			seen[key] = true
+		}
+
+		agent := ai.NewAgentWithRules(rules, 6, 1)
+		random := rand.New(rand.NewSource(6))
+		played := make([][]core.GameEvent, 0)
+		for i := 0; i < 12; i++ {
+			// the search is slow with three reversals, so it gets a deadline
+			agent.Deadline = time.Now().Add(100 * time.Millisecond)
+			m, e := agent.MakeMove()
+			if len(m.Coords) > reversals {
+				t.Fatalf("%d reversals: agent reversed %d tiles", reversals, len(m.Coords))
+			}
+			if !game.IsMoveLocationsEmpty(m) {
+				t.Fatalf("%d reversals: agent's move %v isn't legal", reversals, m.Coords)
+			}
+			agent.ReverseEvents(e)
+			if err := GamesAreEqual(game, agent.Game); err != nil {
+				t.Fatalf("%d reversals: undoing the agent's move: %s", reversals, err)
+			}
+
+			moves := game.GenerateLegalMoves()
+			m = moves[random.Intn(len(moves))]
+			game.AcceptMove(m)
+			played = append(played, agent.AcceptMove(m))
+		}
+		for i := len(played) - 1; i >= 0; i-- {
+			agent.ReverseEvents(played[i])
+		}
+		if err := GamesAreEqual(agent.Game, core.NewGameWithRules(6, rules)); err != nil {
+			t.Fatalf("%d reversals: undoing the game: %s", reversals, err)
+		}
+	}
+}
+
+func TestMoveDeadline(t *testing.T) {
+	game := core.NewGameWithSeed(3)
+	agent := ai.NewAgent(3, 0)
+
+	start := time.Now()
+	agent.Deadline = start.Add(20 * time.Millisecond)
+	_, e := agent.MakeMove()
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("agent took %s with a 20ms deadline", d)
+	}
+
+	agent.ReverseEvents(e)
+	if err := GamesAreEqual(game, agent.Game); err != nil {
+		t.Fatalf(err.Error())
+	}
+}
+
+func TestIterativeDeepening(t *testing.T) {
+	// without a budget the agent always gets to its depth
+	agent := ai.NewAgent(3, 0)
+	agent.Depth = 2
+	agent.MakeMove()
+	if agent.Stats.Depth != 2 {
+		t.Fatalf("agent only reached depth %d", agent.Stats.Depth)
+	}
+
+	depths := make([]int, 0)
+	for _, budget := range []int{2000, 50000} {
+		game := core.NewGameWithSeed(3)
+		agent := ai.NewAgent(3, 0)
+		agent.Depth = ai.MAX_SEARCH_DEPTH
+		agent.MaxNodes = budget
+		_, e := agent.MakeMove()
+		if agent.Stats.Nodes > budget {
+			t.Fatalf("agent searched %d nodes with a budget of %d", agent.Stats.Nodes, budget)
+		}
+		depths = append(depths, agent.Stats.Depth)
+
+		agent.ReverseEvents(e)
+		if err := GamesAreEqual(game, agent.Game); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if depths[1] <= depths[0] {
+		t.Fatalf("a bigger budget should search deeper, got depths %v", depths)
+	}
+}
+
+func TestTranspositionTable(t *testing.T) {
+	game := core.NewGameWithSeed(3)
+	hash := game.Hash()
+	e := game.AcceptMove(game.GenerateLegalMoves()[0])
+	if game.Hash() == hash {
+		t.Fatal("the move didn't change the hash")
+	}
+	game.UndoEvents(e)
+	if game.Hash() != hash {
+		t.Fatal("undoing the move didn't restore the hash")
+	}
+
+	// the same search should visit far fewer nodes when it can look up positions
+	nodes := make([]int, 0)
+	for _, table := range []*ai.TranspositionTable{nil, ai.NewTranspositionTable(ai.DEFAULT_TABLE_SIZE)} {
+		agent := ai.NewAgent(3, 0)
+		agent.Depth = 3
+		agent.Table = table
+		agent.TurnsTaken = 2
+		agent.MakeMove()
+		nodes = append(nodes, agent.Stats.Nodes)
+	}
+	if nodes[1] >= nodes[0] {
+		t.Fatalf("searched %d nodes with the table and %d without", nodes[1], nodes[0])
+	}
+
+	// armor and experience can't spill into each other
+	c := game.EastCreatures[0]
+	c.Armor = 64
+	armored := game.Hash()
+	c.Armor, c.Experience = 0, 1
+	if game.Hash() == armored {
+		t.Fatal("armor and experience have the same hash")
+	}
+	c.Experience = 1 << 8
+	if game.Hash() == hash {
+		t.Fatal("experience is cut off from the hash")
+	}
+
+	// the table is cleared for a new game, the positions would have the same hashes.
+	// without ordering only the table changes how the search goes
+	rules := core.DefaultRules()
+	rules.SuddenDeath = core.SuddenDeath{Turn: 1, DamageMultiplier: 2, EffectBonus: 2}
+	fresh := ai.NewAgentWithRules(rules, 3, 0)
+	reused := ai.NewAgent(3, 0)
+	for _, agent := range []*ai.Agent{fresh, reused} {
+		agent.Depth = 3
+		agent.TurnsTaken = 2
+		agent.Ordering = false
+	}
+	reused.MakeMove()
+	reused.Game = core.NewGameWithRules(3, rules)
+	fresh.MakeMove()
+	reused.MakeMove()
+	if fresh.Stats.Nodes != reused.Stats.Nodes {
+		t.Fatalf("searched %d nodes after another game and %d in a fresh agent", reused.Stats.Nodes, fresh.Stats.Nodes)
+	}
+}
+
+func TestMoveOrdering(t *testing.T) {
+	// killers, history and tiles in front of creatures should lead to earlier cutoffs
+	stats := make([]ai.SearchStats, 0)
+	for _, ordering := range []bool{false, true} {
+		agent := ai.NewAgent(5, 0)
+		agent.Depth = 3
+		agent.Ordering = ordering
+		agent.TurnsTaken = 2
+		agent.MakeMove()
+		stats = append(stats, agent.Stats)
+	}
+	if stats[1].BranchingFactor() >= stats[0].BranchingFactor() || stats[1].Nodes >= stats[0].Nodes {
+		t.Fatalf("ordered search had branching factor %.2f and %d nodes, unordered had %.2f and %d",
+			stats[1].BranchingFactor(), stats[1].Nodes, stats[0].BranchingFactor(), stats[0].Nodes)
+	}
+}
+
+func TestParallelSearch(t *testing.T) {
+	// workers should pick the same moves as a single search with the same random seed
+	for seed := int64(0); seed < 3; seed++ {
+		sequential := ai.NewAgent(seed, seed)
+		parallel := ai.NewAgent(seed, seed)
+		sequential.Depth = 2
+		parallel.Depth = 2
+		parallel.Workers = 4
+		for i := 0; i < 3; i++ {
+			m1, _ := sequential.MakeMove()
+			m2, _ := parallel.MakeMove()
+			if fmt.Sprint(m1) != fmt.Sprint(m2) {
+				t.Fatalf("seed %d move %d: parallel search played %v instead of %v", seed, i, m2, m1)
+			}
+		}
+	}
+}
+
+func TestHandicapReplay(t *testing.T) {
+	s := rand.NewSource(4)
+	random := rand.New(s)
+
+	rules := core.DefaultRules()
+	rules.WestHandicap = core.Handicap{ExtraHealth: 10, ExtraPower: 1, ExtraReversals: 1, MovesFirst: true}
+	agent := ai.NewAgentWithRules(rules, 4, 4)
+	if agent.Game.CurrentTurn != core.WEST || agent.Game.WestHealth != 60 {
+		t.Fatalf("handicap was not applied")
+	}
+
+	record := core.NewGameRecord(agent.Game)
+	for i := 0; i < 10; i++ {
+		legalMoves := agent.Game.GenerateLegalMoves()
+		m := legalMoves[random.Intn(len(legalMoves))]
+		agent.AcceptMove(m)
+		record.AddMove(m)
+	}
+
+	agent.Deadline = time.Now().Add(200 * time.Millisecond)
+	m, _ := agent.MakeMove()
+	record.AddMove(m)
+
+	if err := GamesAreEqual(record.Replay(), agent.Game); err != nil {
+		t.Fatalf(err.Error())
+	}
+}
+
+func TestReverseSimultaneous(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.Simultaneous = true
+	game := core.NewGameWithRules(5, rules)
+	agent := ai.NewAgentWithRules(rules, 5, 5)
+
+	for i := 0; i < 6; i++ {
+		east := agent.MakeSimultaneousMove(core.EAST)
+		west := agent.MakeSimultaneousMove(core.WEST)
+		e := agent.AcceptSimultaneousMoves(east, west)
+		agent.ReverseEvents(e)
+		if err := GamesAreEqual(game, agent.Game); err != nil {
+			t.Fatalf(err.Error())
+		}
+
+		game.AcceptSimultaneousMoves(east, west)
+		agent.AcceptSimultaneousMoves(east, west)
+	}
+	if err := GamesAreEqual(game, agent.Game); err != nil {
+		t.Fatalf(err.Error())
+	}
+}
+
+func TestWinConditions(t *testing.T) {
+	s := rand.NewSource(6)
+	random := rand.New(s)
+
+	rules := core.DefaultRules()
+	rules.WinConditions = []core.WinCondition{core.HealthAfterTurnsCondition{Turns: 12}}
+	agent := ai.NewAgentWithRules(rules, 6, 6)
+
+	var e []core.GameEvent
+	for !agent.Game.IsOver() {
+		legalMoves := agent.Game.GenerateLegalMoves()
+		e = agent.AcceptMove(legalMoves[random.Intn(len(legalMoves))])
+	}
+	game := agent.Game
+	if game.TurnNumber != 12 || game.Result != core.HEALTH_AFTER_TURNS {
+		t.Fatalf("game ended at turn %d with result %d", game.TurnNumber, game.Result)
+	}
+	if (game.Winner == 0) != (game.EastHealth == game.WestHealth) {
+		t.Fatalf("wrong winner %s with health %d %d", game.Winner, game.EastHealth, game.WestHealth)
+	}
+
+	agent.ReverseEvents(e)
+	if game.IsOver() || game.TurnNumber != 11 {
+		t.Fatalf("undoing the last move didn't undo the end of the game")
+	}
+}
+
+func TestSelfPlay(t *testing.T) {
+	rules := core.DefaultRules()
+	east := ai.NewAgentWithRules(rules, 4, 1)
+	west := ai.NewAgentWithRules(rules, 4, 2)
+	east.Depth = 0
+	west.Depth = 0
+	res := ai.SelfPlay(rules, 4, east, west, 100)
+
+	// the record should reproduce the finished game
+	replayed := res.Record.Replay()
+	if replayed.Winner != res.Winner || replayed.TurnNumber != res.Turns {
+		t.Fatalf("replay ended with winner %d after %d turns, expected %d after %d", replayed.Winner, replayed.TurnNumber, res.Winner, res.Turns)
+	}
+	if replayed.EastHealth-replayed.WestHealth != res.HealthMargin {
+		t.Fatalf("replay has a health margin of %d, expected %d", replayed.EastHealth-replayed.WestHealth, res.HealthMargin)
+	}
+
+	config := ai.DEFAULT_BALANCE_CONFIG
+	config.MinFirstWinRate = 0
+	config.MaxFirstWinRate = 1
+	if _, ok, err := ai.FindBalancedSeed(context.Background(), rules, config, rand.New(rand.NewSource(1))); !ok || err != nil {
+		t.Fatalf("any map should be accepted when the band covers every win rate, got %v", err)
+	}
+}
+
+func TestBalancedSeed(t *testing.T) {
+	rules := core.DefaultRules()
+	ctx := context.Background()
+	config := ai.DEFAULT_BALANCE_CONFIG
+	config.GamesPerSide = 1
+	config.Candidates = 10
+
+	// go through the same candidates as FindBalancedSeed until one has a different win
+	// rate than the first, and make the band take only that one
+	random := rand.New(rand.NewSource(1))
+	rates := make([]float64, 0)
+	var seed int64
+	for len(rates) == 0 || rates[len(rates)-1] == rates[0] {
+		if len(rates) == config.Candidates {
+			t.Fatalf("every candidate had a win rate of %.2f", rates[0])
+		}
+		seed = random.Int63()
+		rate, err := ai.FirstPlayerWinRate(ctx, rules, seed, config, random)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rates = append(rates, rate)
+	}
+	config.MinFirstWinRate = rates[len(rates)-1]
+	config.MaxFirstWinRate = rates[len(rates)-1]
+	found, ok, err := ai.FindBalancedSeed(ctx, rules, config, rand.New(rand.NewSource(1)))
+	if err != nil || !ok || found != seed {
+		t.Fatalf("expected map %d to be accepted after %d were rejected, got %d, %v, %v", seed, len(rates)-1, found, ok, err)
+	}
+
+	// nothing fits an empty band, so the most balanced candidate is used
+	config.Candidates = 2
+	config.MinFirstWinRate = 1
+	config.MaxFirstWinRate = 0
+	if _, ok, err := ai.FindBalancedSeed(ctx, rules, config, rand.New(rand.NewSource(1))); ok || err != nil {
+		t.Fatalf("an empty band accepted a map, %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := ai.FindBalancedSeed(cancelled, rules, config, rand.New(rand.NewSource(1))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the search to be cancelled, got %v", err)
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	rules := core.DefaultRules()
+	agent := ai.NewAgentWithRules(rules, 5, 1)
+	agent.Depth = 0
+	res, err := ai.PlayGame(context.Background(), rules, 5, agent, ai.NewRandomPlayer(2), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Record.Replay().TurnNumber != res.Turns {
+		t.Fatal("the record doesn't match the game")
+	}
+
+	// the view is a copy, so searching it leaves the game alone
+	game := core.NewGameWithSeed(5)
+	view := core.NewGameView(game, core.EAST)
+	view.Game().AcceptMove(core.NewGameMove())
+	if err := GamesAreEqual(game, core.NewGameWithSeed(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	// a cancelled search still comes up with a move right away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if _, err := ai.NewAgent(5, 1).ChooseMove(ctx, view); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Fatalf("agent took %s after being cancelled", d)
+	}
+}
+
+func TestMCTS(t *testing.T) {
+	// even a small tree search should beat random moves from either side
+	wins := 0
+	for seed := int64(0); seed < 2; seed++ {
+		mcts := ai.NewMCTSPlayer(seed)
+		mcts.Iterations = 200
+		east, west := core.Player(mcts), core.Player(ai.NewRandomPlayer(seed))
+		side := core.EAST
+		if seed == 1 {
+			east, west, side = west, east, core.WEST
+		}
+		res, err := ai.PlayGame(context.Background(), core.DefaultRules(), seed, east, west, 200)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Winner == side {
+			wins += 1
+		}
+	}
+	if wins < 2 {
+		t.Fatalf("mcts only won %d of 2 games against random moves", wins)
+	}
+
+	rules := core.DefaultRules()
+	rules.Simultaneous = true
+	mcts := ai.NewMCTSPlayer(3)
+	mcts.Iterations = 100
+	if _, err := ai.PlayGame(context.Background(), rules, 3, ai.NewRandomPlayer(3), mcts, 20); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDifficulty(t *testing.T) {
+	// each difficulty searches at least as deep as the one below it, with no more noise
+	// and blunders, and is better in at least one of them
+	for i := 0; i+1 < len(ai.DIFFICULTIES); i++ {
+		weaker, stronger := ai.DIFFICULTIES[i].Settings(), ai.DIFFICULTIES[i+1].Settings()
+		if stronger.Depth < weaker.Depth || stronger.Noise > weaker.Noise || stronger.BlunderChance > weaker.BlunderChance {
+			t.Fatalf("%v %+v is weaker than %v %+v in some way", ai.DIFFICULTIES[i+1], stronger, ai.DIFFICULTIES[i], weaker)
+		}
+		if stronger == weaker {
+			t.Fatalf("%v and %v have the same settings", ai.DIFFICULTIES[i+1], ai.DIFFICULTIES[i])
+		}
+	}
+
+	expert := ai.EXPERT.Settings()
+	if expert.Depth != ai.DEFAULT_DEPTH || expert.Noise != 0 || expert.BlunderChance != 0 {
+		t.Fatalf("expert should be the agent at full strength, got %+v", expert)
+	}
+	agent := ai.NewAgent(1, 1)
+	agent.SetDifficulty(ai.BEGINNER)
+	if s := ai.BEGINNER.Settings(); agent.Depth != s.Depth || agent.Noise != s.Noise || agent.BlunderChance != s.BlunderChance {
+		t.Fatal("the agent doesn't have the beginner settings")
+	}
+}
+
+func TestWeights(t *testing.T) {
+	weights := ai.DEFAULT_WEIGHTS
+	for f := ai.Feature(0); f < ai.FEATURE_COUNT; f++ {
+		weights[f] = float64(f) + 0.5
+	}
+	var buf bytes.Buffer
+	if err := weights.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ai.ReadWeights(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != weights {
+		t.Fatalf("read %v after writing %v", read, weights)
+	}
+
+	// features left out of the file keep their default weight
+	read, err = ai.ReadWeights(strings.NewReader(`{"threatened_creatures": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read[ai.THREATENED_CREATURES_FEATURE] != 2 || read[ai.HEALTH_FEATURE] != ai.DEFAULT_WEIGHTS[ai.HEALTH_FEATURE] {
+		t.Fatalf("read %v", read)
+	}
+	if _, err := ai.ReadWeights(strings.NewReader(`{"luck": 1}`)); err == nil {
+		t.Fatal("an unknown feature was accepted")
+	}
+
+	// every feature should be usable in a search
+	rules := core.DefaultRules()
+	rules.Armor = true
+	east := ai.NewAgentWithRules(rules, 6, 1)
+	east.Depth = 1
+	east.Weights = weights
+	west := ai.NewAgentWithRules(rules, 6, 2)
+	west.Depth = 1
+	res := ai.SelfPlay(rules, 6, east, west, 40)
+	if res.Record.Replay().TurnNumber != res.Turns {
+		t.Fatal("the record doesn't match the game")
+	}
+}
+
+func TestTune(t *testing.T) {
+	config := ai.DEFAULT_TUNE_CONFIG
+	config.Pairs = 1
+	config.Depth = 0
+	config.MaxTurns = 40
+	config.Workers = 2
+
+	straight := ai.NewTuneState(7, ai.DEFAULT_WEIGHTS)
+	if err := ai.Tune(context.Background(), config, straight, 3, nil); err != nil {
+		t.Fatal(err)
+	}
+	if straight.Iteration != 3 || straight.Weights == ai.DEFAULT_WEIGHTS {
+		t.Fatalf("weights after %d iterations: %v", straight.Iteration, straight.Weights)
+	}
+
+	// a run that is stopped and resumed from its checkpoint should end up the same
+	path := t.TempDir() + "/tune.json"
+	resumed := ai.NewTuneState(7, ai.DEFAULT_WEIGHTS)
+	save := func(s *ai.TuneState) error { return ai.SaveTuneState(path, s) }
+	if err := ai.Tune(context.Background(), config, resumed, 1, save); err != nil {
+		t.Fatal(err)
+	}
+	resumed, err := ai.LoadTuneState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ai.Tune(context.Background(), config, resumed, 3, save); err != nil {
+		t.Fatal(err)
+	}
+	if resumed.Weights != straight.Weights || len(resumed.History) != 3 {
+		t.Fatalf("resumed run ended with %v instead of %v", resumed.Weights, straight.Weights)
+	}
+}
+
+func TestTournament(t *testing.T) {
+	rules := core.DefaultRules()
+	bots := make([]ai.Bot, 0)
+	for _, c := range []ai.BotConfig{
+		{Name: "random", Kind: "random"},
+		{Name: "agent", Kind: "agent", Difficulty: "hard", Depth: 1},
+	} {
+		bot, err := ai.NewBot(c, rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bots = append(bots, bot)
+	}
+	if _, err := ai.NewBot(ai.BotConfig{Name: "x", Kind: "agent", Difficulty: "impossible"}, rules); err == nil {
+		t.Fatal("an unknown difficulty was accepted")
+	}
+
+	// the results shouldn't depend on how many games are played at once
+	config := ai.TournamentConfig{Rules: rules, Seeds: []int64{1, 2, 3}, MaxTurns: 200, Workers: 1}
+	sequential, err := ai.RunTournament(context.Background(), config, bots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Workers = 3
+	parallel, err := ai.RunTournament(context.Background(), config, bots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parallel.Games) != 6 {
+		t.Fatalf("played %d games instead of 6", len(parallel.Games))
+	}
+	for i := range parallel.Games {
+		if parallel.Games[i].Result.Winner != sequential.Games[i].Result.Winner || parallel.Games[i].Result.Turns != sequential.Games[i].Result.Turns {
+			t.Fatalf("game %d ended differently when played in parallel", i)
+		}
+	}
+
+	pairings := parallel.Pairings()
+	if pairings[0][1].Wins != pairings[1][0].Losses || pairings[0][1].TotalMargin != -pairings[1][0].TotalMargin {
+		t.Fatalf("pairings don't match: %v", pairings)
+	}
+	ratings := parallel.Ratings()
+	if ratings[1].Elo <= ratings[0].Elo {
+		t.Fatalf("the agent should be rated above random moves, got %v", ratings)
+	}
+	for _, r := range ratings {
+		if r.Low > r.Elo || r.High < r.Elo {
+			t.Fatalf("rating outside of its confidence interval: %v", r)
+		}
+	}
+	var buf bytes.Buffer
+	if err := parallel.Write(&buf); err != nil || !strings.Contains(buf.String(), "random") {
+		t.Fatalf("couldn't write the results: %v", err)
+	}
+}
+
+func TestReserveSwaps(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.ReserveSwaps = true
+	game := core.NewGameWithRules(6, rules)
+	game2 := core.NewGameWithRules(6, rules)
+
+	swaps := game.GenerateSwapMoves(core.NewGameMove(game.AllCoords[0]))
+	if len(swaps) == 0 {
+		t.Fatal("expected some reserve swaps at the start of the game")
+	}
+	m := swaps[len(swaps)-1]
+	c := game.EastCreatures[m.Swaps[0]]
+	front := game.EastCreatures[game.ReserveFront(core.EAST, c.Y)]
+	frontX := front.X
+	events := game.AcceptMove(m)
+	// the swapped creature was at the back, so it should have taken the place of the front
+	if c.X != frontX+1 {
+		t.Fatalf("swapped creature is at %d, expected %d", c.X, frontX+1)
+	}
+
+	game.UndoEvents(events)
+	if err := GamesAreEqual(game, game2); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := ai.NewAgentWithRules(rules, 6, 1)
+	agent.Depth = 1
+	for i := 0; i < 4; i++ {
+		m, _ := agent.MakeMove()
+		if m.Size() > agent.Game.ReversalsAllowed(agent.Game.CurrentTurn.Opposite()) {
+			t.Fatalf("agent move uses %d reversals", m.Size())
+		}
+	}
+}
+
+func TestPowers(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.Powers = true
+	game := core.NewGameWithRules(7, rules)
+	record := core.NewGameRecord(game)
+	play := func(m core.GameMove) []core.GameEvent {
+		record.AddMove(m)
+		return game.AcceptMove(m)
+	}
+
+	// freeze a tile after reversing it, so the opponent can't reverse it back
+	c := game.EmptyCoords()[0]
+	play(core.NewGameMove(c).WithPower(core.PowerMove{Power: core.FREEZE, Target: c}))
+	if game.PowerReady(core.EAST, core.FREEZE) {
+		t.Fatal("freeze should be cooling down")
+	}
+	for _, m := range game.GenerateLegalMoves() {
+		if m.Contains(c) {
+			t.Fatal("frozen tile can be reversed")
+		}
+	}
+	play(core.NewGameMove(c))
+	if !game.Map.Tiles[c.X][c.Y].Reversed {
+		t.Fatal("frozen tile was reversed")
+	}
+
+	// shield a creature on the board and make sure every power can be undone
+	for i := 0; i < 30 && !game.IsOver(); i++ {
+		moves := game.GeneratePowerMoves()
+		for _, p := range moves {
+			before := record.Replay()
+			events := game.AcceptMove(core.GameMove{}.WithPower(p))
+			game.UndoEvents(events)
+			if err := GamesAreEqual(game, before); err != nil {
+				t.Fatalf("undoing %s: %s", p.Power, err)
+			}
+		}
+		m := game.GenerateLegalMoves()[i%7]
+		if len(moves) > 0 {
+			m = m.WithPower(moves[len(moves)-1])
+		}
+		play(m)
+	}
+	if err := GamesAreEqual(game, record.Replay()); err != nil {
+		t.Fatalf("replay doesn't match the game: %s", err)
+	}
+}
+
+func TestUndoRandomMoves(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		// every other game also checks the rules that add events to collisions
+		rules := core.DefaultRules()
+		rules.Leveling = seed%2 == 1
+		rules.Powers = seed%2 == 1
+		rules.Armor = seed%2 == 1
+		game := core.NewGameWithRules(seed, rules)
+		record := core.NewGameRecord(game)
+		random := rand.New(rand.NewSource(seed))
+
+		for i := 0; i < 60 && !game.IsOver(); i++ {
+			moves := game.GenerateLegalMoves()
+			m := moves[random.Intn(len(moves))]
+			if powers := game.GeneratePowerMoves(); len(powers) > 0 {
+				m = m.WithPower(powers[random.Intn(len(powers))])
+			}
+			game.UndoEvents(game.AcceptMove(m))
+			if err := GamesAreEqual(game, record.Replay()); err != nil {
+				t.Fatalf("seed %d turn %d: %s", seed, i, err)
+			}
+			game.AcceptMove(m)
+			record.AddMove(m)
+		}
+	}
+}
+
+func TestNoLegalMoves(t *testing.T) {
+	for _, simultaneous := range []bool{false, true} {
+		rules := core.DefaultRules()
+		rules.Simultaneous = simultaneous
+		agent := ai.NewAgentWithRules(rules, 4, 4)
+		for _, c := range agent.Game.AllCoords {
+			agent.Game.Map.Tiles[c.X][c.Y].FrozenUntil = 100
+		}
+
+		var m core.GameMove
+		if simultaneous {
+			m = agent.MakeSimultaneousMove(core.EAST)
+		} else {
+			m, _ = agent.MakeMove()
+		}
+		if len(m.Coords) != 0 {
+			t.Fatalf("agent reversed %v with every tile frozen", m.Coords)
+		}
+	}
+}
+
 func GamesAreEqual(g1, g2 *core.Game) error {
 	if g1.CurrentTurn != g2.CurrentTurn {
 		return errors.New("Wrong player turn")
@@ -59,14 +774,32 @@ func GamesAreEqual(g1, g2 *core.Game) error {
 	if g1.EastHealth != g2.EastHealth {
 		return errors.New("East Health different")
 	}
+	if g1.TurnNumber != g2.TurnNumber {
+		return errors.New("Turn number different")
+	}
+	if g1.EastKills != g2.EastKills || g1.WestKills != g2.WestKills {
+		return errors.New("Kills different")
+	}
+	if g1.Winner != g2.Winner || g1.Result != g2.Result {
+		return errors.New("Game result different")
+	}
+	if g1.EastPowersReady != g2.EastPowersReady || g1.WestPowersReady != g2.WestPowersReady {
+		return errors.New("Power cooldowns different")
+	}
 	for _, c := range g1.AllCoords {
 		t1 := g1.Map.Tiles[c.X][c.Y]
 		t2 := g2.Map.Tiles[c.X][c.Y]
 		if t1.Reversed != t2.Reversed {
 			return fmt.Errorf("tile at %d %d has different values for Reversed", c.X, c.Y)
 		}
-		if t1.HasCreature != t2.HasCreature {
-			return fmt.Errorf("tile at %d %d has different values for HasCreature", c.X, c.Y)
+		if t1.ReversedBy != t2.ReversedBy {
+			return fmt.Errorf("tile at %d %d has different values for ReversedBy", c.X, c.Y)
+		}
+		if t1.CreatureCount != t2.CreatureCount {
+			return fmt.Errorf("tile at %d %d has different values for CreatureCount", c.X, c.Y)
+		}
+		if t1.FrozenUntil != t2.FrozenUntil {
+			return fmt.Errorf("tile at %d %d has different values for FrozenUntil", c.X, c.Y)
 		}
 		if !EffectsAreEqual(t1.ReverseEffect, t2.ReverseEffect) {
 			return fmt.Errorf("tile at %d %d has different values for ReverseEffect", c.X, c.Y)
@@ -91,6 +824,15 @@ func GamesAreEqual(g1, g2 *core.Game) error {
 		if c1.Power != c2.Power {
 			return fmt.Errorf("east creature at index %d has different power values %d %d", i, c1.Power, c2.Power)
 		}
+		if c1.Experience != c2.Experience {
+			return fmt.Errorf("east creature at index %d has different experience %d %d", i, c1.Experience, c2.Experience)
+		}
+		if c1.Shielded != c2.Shielded {
+			return fmt.Errorf("east creature at index %d has different Shielded values %t %t", i, c1.Shielded, c2.Shielded)
+		}
+		if c1.Armor != c2.Armor {
+			return fmt.Errorf("east creature at index %d has different Armor values %d %d", i, c1.Armor, c2.Armor)
+		}
 	}
 	for i := 0; i < len(g1.WestCreatures); i++ {
 		c1 := g1.WestCreatures[i]
@@ -107,11 +849,20 @@ func GamesAreEqual(g1, g2 *core.Game) error {
 		if c1.Power != c2.Power {
 			return fmt.Errorf("west creature at index %d has different power values %d %d", i, c1.Power, c2.Power)
 		}
+		if c1.Experience != c2.Experience {
+			return fmt.Errorf("west creature at index %d has different experience %d %d", i, c1.Experience, c2.Experience)
+		}
+		if c1.Shielded != c2.Shielded {
+			return fmt.Errorf("west creature at index %d has different Shielded values %t %t", i, c1.Shielded, c2.Shielded)
+		}
+		if c1.Armor != c2.Armor {
+			return fmt.Errorf("west creature at index %d has different Armor values %d %d", i, c1.Armor, c2.Armor)
+		}
 	}
 
 	return nil
 }
 
 func EffectsAreEqual(e1, e2 core.Effect) bool {
-	return e1.X == e2.X && e1.Y == e2.Y && e1.Targets == e2.Targets && e1.SpeciesCondition == e2.SpeciesCondition && e1.ColorCondition == e2.ColorCondition && e1.Value == e2.Value
+	return e1.X == e2.X && e1.Y == e2.Y && e1.Targets == e2.Targets && e1.SpeciesCondition == e2.SpeciesCondition && e1.ColorCondition == e2.ColorCondition && e1.Value == e2.Value && e1.Kind == e2.Kind
 }
@@ -0,0 +1,272 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// MCTSPlayer chooses moves with Monte Carlo tree search. Instead of searching every move
+// to a fixed depth like the Agent, it plays many quick games from the position, and
+// spends more of them on the moves that have done well so far (UCT). The trees are built
+// from scratch for every move.
+type MCTSPlayer struct {
+	Random *rand.Rand
+	// games played out for each move, zero for no limit
+	Iterations int
+	// time allowed for each move, zero for no limit. the player also stops when the
+	// context of ChooseMove is done
+	MoveTime time.Duration
+	// how much UCT favours moves that have been tried less often over ones that have
+	// done well
+	Exploration float64
+	// turns played in each rollout before the position is scored with the evaluation
+	// instead of playing on to the end
+	RolloutTurns int
+	// rollouts play the best of a few random moves instead of any random move, which is
+	// slower but plays out more like a real game
+	Guided bool
+	// scores the rollouts that don't reach the end of the game
+	Weights Weights
+	Stats   MCTSStats
+}
+
+// MCTSStats describes the player's last search
+type MCTSStats struct {
+	Iterations int
+	// nodes in the tree
+	Nodes int
+	// how often the chosen move was played out
+	Visits int
+}
+
+const DEFAULT_ITERATIONS = 4000
+
+const DEFAULT_ROLLOUT_TURNS = 16
+
+// random moves compared in each turn of a guided rollout
+const ROLLOUT_SAMPLES = 4
+
+// how far ahead a side has to be at the end of a rollout for it to count as most of a win
+const ROLLOUT_SCALE = 20
+
+func NewMCTSPlayer(seed int64) *MCTSPlayer {
+	return &MCTSPlayer{
+		Random:       rand.New(rand.NewSource(seed)),
+		Iterations:   DEFAULT_ITERATIONS,
+		Exploration:  math.Sqrt2,
+		RolloutTurns: DEFAULT_ROLLOUT_TURNS,
+		Weights:      DEFAULT_WEIGHTS,
+	}
+}
+
+type mctsNode struct {
+	Move   core.GameMove
+	Parent *mctsNode
+	// the side that played the move. the rewards are from its point of view
+	Side     core.Alignment
+	Children []*mctsNode
+	Visits   int
+	// 1 for each win, 0 for each loss and something in between for a draw or an unfinished
+	// rollout
+	Reward float64
+	// moves that don't have a child yet, in random order
+	untried  []core.GameMove
+	expanded bool
+}
+
+func (p *MCTSPlayer) ChooseMove(ctx context.Context, view core.GameView) (core.GameMove, error) {
+	g := view.Game()
+	side := view.Side
+	p.Stats = MCTSStats{}
+
+	var stopAt time.Time
+	if p.MoveTime > 0 {
+		stopAt = time.Now().Add(p.MoveTime)
+	}
+	root := &mctsNode{Side: side.Opposite()}
+	for p.Iterations <= 0 || p.Stats.Iterations < p.Iterations {
+		if ctx.Err() != nil || (!stopAt.IsZero() && time.Now().After(stopAt)) {
+			break
+		}
+		p.iterate(g, root, side)
+		p.Stats.Iterations += 1
+		// the only move doesn't need any thought
+		if len(root.Children) == 1 && len(root.untried) == 0 {
+			break
+		}
+	}
+
+	// the move played out most often is the one the search trusts most
+	var best *mctsNode
+	for _, c := range root.Children {
+		if best == nil || c.Visits > best.Visits {
+			best = c
+		}
+	}
+	if best == nil {
+		// no time to try anything, or no legal moves at all
+		moves := view.LegalMoves()
+		if len(moves) == 0 {
+			return core.GameMove{}, nil
+		}
+		return moves[p.Random.Intn(len(moves))], nil
+	}
+	p.Stats.Visits = best.Visits
+	return best.Move, nil
+}
+
+// iterate walks down the tree to a move that hasn't been tried, plays the rest of the
+// game randomly and counts the result for every move on the way. The game is put back the
+// way it was afterwards
+func (p *MCTSPlayer) iterate(g *core.Game, root *mctsNode, side core.Alignment) {
+	played := make([][]core.GameEvent, 0, 2*p.RolloutTurns)
+	node := root
+	for !g.IsOver() {
+		// in the simultaneous variant the opponent's move isn't known, so only the root
+		// gets a tree and the opponent plays randomly
+		if g.Rules.Simultaneous && node != root {
+			break
+		}
+		child, isNew := p.descend(g, node, side)
+		if child == nil {
+			break
+		}
+		played = append(played, p.play(g, child.Move, side))
+		node = child
+		if isNew {
+			break
+		}
+	}
+
+	for t := 0; t < p.RolloutTurns && !g.IsOver(); t++ {
+		if g.Rules.Simultaneous {
+			played = append(played, p.play(g, p.rolloutMove(g, side), side))
+		} else {
+			played = append(played, g.AcceptMove(p.rolloutMove(g, g.CurrentTurn)))
+		}
+	}
+	reward := p.score(g, side)
+
+	for i := len(played) - 1; i >= 0; i-- {
+		g.UndoEvents(played[i])
+	}
+	for n := node; n != nil; n = n.Parent {
+		n.Visits += 1
+		if n.Side == side {
+			n.Reward += reward
+		} else {
+			n.Reward += 1 - reward
+		}
+	}
+}
+
+// descend picks the child of the node to play next. A move that hasn't been tried gets a
+// new child, which is reported with true. Otherwise the child with the best UCT score is
+// picked. It returns nil if there's nothing to play
+func (p *MCTSPlayer) descend(g *core.Game, node *mctsNode, side core.Alignment) (*mctsNode, bool) {
+	mover := g.CurrentTurn
+	if g.Rules.Simultaneous {
+		mover = side
+	}
+	if !node.expanded {
+		node.expanded = true
+		turn := g.CurrentTurn
+		g.CurrentTurn = mover
+		node.untried = g.GenerateLegalMoves()
+		g.CurrentTurn = turn
+		p.Random.Shuffle(len(node.untried), func(i, j int) {
+			node.untried[i], node.untried[j] = node.untried[j], node.untried[i]
+		})
+	}
+
+	if len(node.untried) > 0 {
+		m := node.untried[len(node.untried)-1]
+		node.untried = node.untried[:len(node.untried)-1]
+		child := &mctsNode{Move: m, Parent: node, Side: mover}
+		node.Children = append(node.Children, child)
+		p.Stats.Nodes += 1
+		return child, true
+	}
+
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	logVisits := math.Log(float64(node.Visits))
+	for _, c := range node.Children {
+		score := c.Reward/float64(c.Visits) + p.Exploration*math.Sqrt(logVisits/float64(c.Visits))
+		if score > bestScore {
+			best = c
+			bestScore = score
+		}
+	}
+	return best, false
+}
+
+// play applies a move of the side. In the simultaneous variant the opponent plays a random
+// move at the same time
+func (p *MCTSPlayer) play(g *core.Game, m core.GameMove, side core.Alignment) []core.GameEvent {
+	if !g.Rules.Simultaneous {
+		return g.AcceptMove(m)
+	}
+	o := p.rolloutMove(g, side.Opposite())
+	if side == core.EAST {
+		return g.AcceptSimultaneousMoves(m, o)
+	}
+	return g.AcceptSimultaneousMoves(o, m)
+}
+
+// rolloutMove picks a move for the side quickly, without generating every legal move
+func (p *MCTSPlayer) rolloutMove(g *core.Game, side core.Alignment) core.GameMove {
+	coords := g.EmptyCoords()
+	if !p.Guided || g.Rules.Simultaneous {
+		return p.randomMove(g, coords, side)
+	}
+
+	// keep the move that looks best right after it's played
+	var best core.GameMove
+	bestVal := math.MinInt
+	for i := 0; i < ROLLOUT_SAMPLES; i++ {
+		m := p.randomMove(g, coords, side)
+		e := g.AcceptMove(m)
+		val := -p.Weights.Evaluate(g, 0)
+		g.UndoEvents(e)
+		if val > bestVal {
+			best = m
+			bestVal = val
+		}
+	}
+	return best
+}
+
+func (p *MCTSPlayer) randomMove(g *core.Game, coords []core.MapCoord, side core.Alignment) core.GameMove {
+	n := g.ReversalsAllowed(side)
+	if n > len(coords) {
+		n = len(coords)
+	}
+	for i := 0; i < n; i++ {
+		j := i + p.Random.Intn(len(coords)-i)
+		coords[i], coords[j] = coords[j], coords[i]
+	}
+	return core.NewGameMove(append([]core.MapCoord(nil), coords[:n]...)...)
+}
+
+// score is the reward of the rollout for the side. Rollouts that didn't reach the end of
+// the game are scored by how far ahead the side is
+func (p *MCTSPlayer) score(g *core.Game, side core.Alignment) float64 {
+	if g.IsOver() {
+		if g.Winner == side {
+			return 1
+		} else if g.Winner == side.Opposite() {
+			return 0
+		}
+		return 0.5
+	}
+	val := p.Weights.Evaluate(g, 0)
+	if g.CurrentTurn != side {
+		val = -val
+	}
+	return 0.5 + 0.5*math.Tanh(float64(val)/ROLLOUT_SCALE)
+}
@@ -0,0 +1,123 @@
+package ai
+
+import "github.com/prizelobby/reverset-raiders/core"
+
+// move ordering puts the moves most likely to cause a cutoff first, so that the alpha beta
+// search can skip more of the rest. In order of importance:
+const (
+	// the best move the table has for the position
+	TABLE_MOVE_SCORE = 1 << 50
+	// moves that caused a cutoff at the same depth elsewhere in the tree
+	KILLER_SCORE = 1 << 40
+	// for each creature about to step onto the tile, see frontTiles
+	FRONT_TILE_SCORE = 1 << 32
+	// then the history score, which adds up the cutoffs each tile was part of
+)
+
+const KILLERS_PER_DEPTH = 2
+
+type scoredMove struct {
+	Move core.GameMove
+	// int64 so that the tiers fit on 32 bit platforms too
+	Score int64
+}
+
+// orderMoves returns the legal moves from the list, best first. Without Ordering only the
+// table move is brought to the front. The slice is reused by the next search at the
+// same depth
+func (a *Agent) orderMoves(moves []core.GameMove, first core.GameMove, depth int) []scoredMove {
+	for len(a.orderBuf) <= depth {
+		a.orderBuf = append(a.orderBuf, nil)
+	}
+	ordered := a.orderBuf[depth][:0]
+
+	var front [core.MAP_WIDTH][2 * core.MAP_HEIGHT]int
+	if a.Ordering {
+		a.frontTiles(&front)
+	}
+	side := sideIndex(a.Game.CurrentTurn)
+	for _, m := range moves {
+		if !a.Game.IsMoveLocationsEmpty(m) {
+			continue
+		}
+		var score int64
+		if len(first.Coords) > 0 && sameCoords(m, first) {
+			score += TABLE_MOVE_SCORE
+		}
+		if a.Ordering {
+			if depth < len(a.killers) {
+				for _, k := range a.killers[depth] {
+					if len(k.Coords) > 0 && sameCoords(m, k) {
+						score += KILLER_SCORE
+					}
+				}
+			}
+			for _, c := range m.Coords {
+				score += int64(front[c.X][c.Y])*FRONT_TILE_SCORE + int64(a.history[side][c.X][c.Y])
+			}
+		}
+		ordered = append(ordered, scoredMove{Move: m, Score: score})
+	}
+
+	// insertion sort, since it's stable and the lists are short and mostly in order
+	for i := 1; i < len(ordered); i++ {
+		for j := i; j > 0 && ordered[j].Score > ordered[j-1].Score; j-- {
+			ordered[j], ordered[j-1] = ordered[j-1], ordered[j]
+		}
+	}
+	a.orderBuf[depth] = ordered
+	return ordered
+}
+
+// frontTiles counts the creatures of either side that will step onto each tile when
+// their side moves next. Reversing that tile changes where they go after it and which
+// effect they pick up
+func (a *Agent) frontTiles(front *[core.MAP_WIDTH][2 * core.MAP_HEIGHT]int) {
+	for _, side := range []core.Alignment{core.EAST, core.WEST} {
+		for _, c := range a.Game.Creatures(side) {
+			if c.Removed {
+				continue
+			}
+			if t := nextTile(a.Game, c); t != nil {
+				front[t.X][t.Y] += 1
+			}
+		}
+	}
+}
+
+// cutoff remembers the move that caused a beta cutoff for ordering later searches
+func (a *Agent) cutoff(move core.GameMove, depth int) {
+	a.Stats.Cutoffs += 1
+	if !a.Ordering {
+		return
+	}
+	for len(a.killers) <= depth {
+		a.killers = append(a.killers, [KILLERS_PER_DEPTH]core.GameMove{})
+	}
+	if !sameCoords(a.killers[depth][0], move) {
+		a.killers[depth][1] = a.killers[depth][0]
+		a.killers[depth][0] = move
+	}
+	side := sideIndex(a.Game.CurrentTurn)
+	for _, c := range move.Coords {
+		a.history[side][c.X][c.Y] += depth * depth
+	}
+}
+
+// ageHistory halves the history between moves, so that old cutoffs matter less
+func (a *Agent) ageHistory() {
+	for s := range a.history {
+		for x := range a.history[s] {
+			for y := range a.history[s][x] {
+				a.history[s][x][y] /= 2
+			}
+		}
+	}
+}
+
+func sideIndex(side core.Alignment) int {
+	if side == core.WEST {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,23 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// RandomPlayer plays random legal moves. It's mostly useful as a weak opponent for
+// testing other players
+type RandomPlayer struct {
+	Random *rand.Rand
+}
+
+func NewRandomPlayer(seed int64) *RandomPlayer {
+	return &RandomPlayer{Random: rand.New(rand.NewSource(seed))}
+}
+
+func (p *RandomPlayer) ChooseMove(ctx context.Context, view core.GameView) (core.GameMove, error) {
+	moves := view.LegalMoves()
+	return moves[p.Random.Intn(len(moves))], nil
+}
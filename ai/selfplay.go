@@ -0,0 +1,161 @@
+package ai
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// SelfPlayResult is the outcome of a game between two agents
+type SelfPlayResult struct {
+	Winner core.Alignment
+	Result core.GameResult
+	// EAST health minus WEST health when the game ended
+	HealthMargin int
+	Turns        int
+	Record       *core.GameRecord
+}
+
+// SelfPlay plays a game between two agents on the map of the given seed. A game that is
+// still going after maxTurns is stopped and counts as a draw.
+func SelfPlay(rules core.Rules, seed int64, east, west *Agent, maxTurns int) SelfPlayResult {
+	// agents only stop early when the context is cancelled, so there can't be an error
+	res, _ := PlayGame(context.Background(), rules, seed, east, west, maxTurns)
+	return res
+}
+
+// PlayGame plays a game between any two players on the map of the given seed, stopping
+// after maxTurns like SelfPlay. It gives up if either player fails to choose a move.
+func PlayGame(ctx context.Context, rules core.Rules, seed int64, east, west core.Player, maxTurns int) (SelfPlayResult, error) {
+	g := core.NewGameWithRules(seed, rules)
+	record := core.NewGameRecord(g)
+
+	for !g.IsOver() && g.TurnNumber < maxTurns {
+		if rules.Simultaneous {
+			em, err := east.ChooseMove(ctx, core.NewGameView(g, core.EAST))
+			if err != nil {
+				return SelfPlayResult{}, err
+			}
+			wm, err := west.ChooseMove(ctx, core.NewGameView(g, core.WEST))
+			if err != nil {
+				return SelfPlayResult{}, err
+			}
+			record.AddMove(em)
+			record.AddMove(wm)
+			record.AddEvents(g.AcceptSimultaneousMoves(em, wm))
+			continue
+		}
+
+		mover := east
+		if g.CurrentTurn == core.WEST {
+			mover = west
+		}
+		m, err := mover.ChooseMove(ctx, core.NewGameView(g, g.CurrentTurn))
+		if err != nil {
+			return SelfPlayResult{}, err
+		}
+		record.AddMove(m)
+		record.AddEvents(g.AcceptMove(m))
+	}
+
+	record.Winner = g.Winner
+	record.Result = g.Result
+	return SelfPlayResult{
+		Winner:       g.Winner,
+		Result:       g.Result,
+		HealthMargin: g.EastHealth - g.WestHealth,
+		Turns:        g.TurnNumber,
+		Record:       record,
+	}, nil
+}
+
+// BalanceConfig controls how hard FindBalancedSeed works to find a fair map
+type BalanceConfig struct {
+	// maps that are tried before giving up
+	Candidates int
+	// games played on each map with each side moving first
+	GamesPerSide int
+	// search depth of the agents, kept low so that the games are fast
+	Depth    int
+	MaxTurns int
+	// a map is accepted when the first player's win rate is within this band. draws
+	// count as half a win
+	MinFirstWinRate float64
+	MaxFirstWinRate float64
+}
+
+var DEFAULT_BALANCE_CONFIG = BalanceConfig{
+	Candidates:      20,
+	GamesPerSide:    4,
+	Depth:           0,
+	MaxTurns:        100,
+	MinFirstWinRate: 0.4,
+	MaxFirstWinRate: 0.6,
+}
+
+// FirstPlayerWinRate plays games between fast agents on the map of the seed, half of them
+// with each side moving first, and returns how often the side that moved first won. It
+// stops with the context's error if the context is done
+func FirstPlayerWinRate(ctx context.Context, rules core.Rules, seed int64, config BalanceConfig, random *rand.Rand) (float64, error) {
+	score := 0.0
+	for _, first := range []core.Alignment{core.EAST, core.WEST} {
+		r := rules
+		r.EastHandicap.MovesFirst = first == core.EAST
+		r.WestHandicap.MovesFirst = first == core.WEST
+
+		for i := 0; i < config.GamesPerSide; i++ {
+			east := NewAgentWithRules(r, seed, random.Int63())
+			west := NewAgentWithRules(r, seed, random.Int63())
+			east.Depth = config.Depth
+			west.Depth = config.Depth
+
+			res, err := PlayGame(ctx, r, seed, east, west, config.MaxTurns)
+			if err == nil {
+				err = ctx.Err()
+			}
+			if err != nil {
+				return 0, err
+			}
+			if res.Winner == first {
+				score += 1
+			} else if res.Winner == 0 {
+				score += 0.5
+			}
+		}
+	}
+	return score / float64(2*config.GamesPerSide), nil
+}
+
+// FindBalancedSeed rolls candidate maps until one of them has a first player win rate
+// inside the configured band. If none of the candidates are good enough the most balanced
+// one is returned along with false. It can take a while, so it gives up with the context's
+// error when the context is done
+func FindBalancedSeed(ctx context.Context, rules core.Rules, config BalanceConfig, random *rand.Rand) (int64, bool, error) {
+	bestSeed := random.Int63()
+	bestDistance := 2.0
+	for i := 0; i < config.Candidates; i++ {
+		seed := bestSeed
+		if i > 0 {
+			seed = random.Int63()
+		}
+
+		rate, err := FirstPlayerWinRate(ctx, rules, seed, config, random)
+		if err != nil {
+			return 0, false, err
+		}
+		if rate >= config.MinFirstWinRate && rate <= config.MaxFirstWinRate {
+			return seed, true, nil
+		}
+
+		distance := rate - 0.5
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < bestDistance {
+			bestSeed = seed
+			bestDistance = distance
+		}
+	}
+	return bestSeed, false, nil
+}
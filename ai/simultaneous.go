@@ -0,0 +1,90 @@
+package ai
+
+import "github.com/prizelobby/reverset-raiders/core"
+
+// MakeSimultaneousMove picks the agent's move for a round of the simultaneous variant
+// without applying it, since the opponent's move isn't known yet. Each candidate is scored
+// by the worst outcome over every move the opponent could pick at the same time, so the
+// agent plays the move that is safest against whatever the opponent chooses.
+func (a *Agent) MakeSimultaneousMove(side core.Alignment) core.GameMove {
+	a.startSearch()
+
+	moves := a.legalMovesFor(side)
+	if len(moves) == 0 {
+		a.TurnsTaken += 1
+		return core.GameMove{}
+	}
+	if a.BlunderChance > 0 && a.Random.Float64() < a.BlunderChance {
+		a.TurnsTaken += 1
+		return moves[a.Random.Intn(len(moves))]
+	}
+	oppMoves := a.legalMovesFor(side.Opposite())
+
+	// start at an offset so that if all the evaluations are the same, we choose
+	// a random move instead of the first move in the array
+	randomOffset := a.Random.Intn(len(moves))
+	move := moves[randomOffset]
+	best := -99999
+	for i := 0; i < len(moves); i++ {
+		m := moves[(i+randomOffset)%len(moves)]
+		// the noise is drawn first, so that pruning compares against the best move minus the
+		// noise, like alphaFor does
+		noise := 0
+		if a.Noise > 0 {
+			noise = a.Random.Intn(2*a.Noise+1) - a.Noise
+		}
+
+		worst := 99999
+		for _, o := range oppMoves {
+			var e []core.GameEvent
+			if side == core.EAST {
+				e = a.Game.AcceptSimultaneousMoves(m, o)
+			} else {
+				e = a.Game.AcceptSimultaneousMoves(o, m)
+			}
+			val := a.evaluationFor(side)
+			a.ReverseEvents(e)
+
+			if val < worst {
+				worst = val
+			}
+			// the opponent already has a reply that makes this worse than the best move
+			if worst <= best-noise || a.outOfTime() {
+				break
+			}
+		}
+		if a.stopped {
+			break
+		}
+
+		worst += noise
+		if worst > best {
+			best = worst
+			move = m
+		}
+	}
+
+	a.TurnsTaken += 1
+	return move
+}
+
+func (a *Agent) AcceptSimultaneousMoves(east, west core.GameMove) []core.GameEvent {
+	return a.Game.AcceptSimultaneousMoves(east, west)
+}
+
+// legalMovesFor generates the legal moves of a side regardless of whose turn it is
+func (a *Agent) legalMovesFor(side core.Alignment) []core.GameMove {
+	turn := a.Game.CurrentTurn
+	a.Game.CurrentTurn = side
+	moves := a.Game.GenerateLegalMoves()
+	a.Game.CurrentTurn = turn
+	return moves
+}
+
+func (a *Agent) evaluationFor(side core.Alignment) int {
+	val := a.evaluation(a.Game, 0)
+	if a.Game.CurrentTurn != side {
+		return -val
+	}
+	return val
+}
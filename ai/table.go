@@ -0,0 +1,131 @@
+package ai
+
+import "github.com/prizelobby/reverset-raiders/core"
+
+type Bound int
+
+const (
+	EXACT Bound = iota
+	// the value is at least this much, the search was cut off by beta
+	LOWER_BOUND
+	// the value is at most this much, no move beat alpha
+	UPPER_BOUND
+)
+
+type TableEntry struct {
+	Hash  uint64
+	Depth int
+	Value int
+	Bound Bound
+	// the best move found, which is searched first the next time
+	Move core.GameMove
+}
+
+// Cutoff tells whether the entry settles the value of a search to the depth with the given
+// window, so that the search can be skipped. Deeper entries aren't used, since then the
+// value would depend on what happened to be searched before, and parallel searches
+// wouldn't be reproducible
+func (e TableEntry) Cutoff(depth int, alpha, beta int) bool {
+	if e.Depth != depth {
+		return false
+	}
+	if e.Bound == EXACT {
+		return true
+	} else if e.Bound == LOWER_BOUND {
+		return e.Value >= beta
+	}
+	return e.Value <= alpha
+}
+
+// TranspositionTable remembers positions that were already searched, so that a position
+// reached by different moves is only searched once. Positions that land on the same slot
+// replace each other, so the table never grows past its size.
+type TranspositionTable struct {
+	Size    int
+	entries []TableEntry
+	used    []bool
+}
+
+const DEFAULT_TABLE_SIZE = 1 << 16
+
+// the searches of the agent give different values for the same position, so each one
+// has its own entries
+const (
+	FULL_MOVE_SEARCH uint64 = 0x5bd1e9955bd1e995
+	PART_MOVE_SEARCH uint64 = 0x27d4eb2f165667c5
+	SEMI_SEARCH      uint64 = 0x85ebca6bc2b2ae35
+	GUIDED_SEARCH    uint64 = 0x9e3779b97f4a7c15
+)
+
+func NewTranspositionTable(size int) *TranspositionTable {
+	return &TranspositionTable{Size: size}
+}
+
+// Probe looks up the position. A nil table never finds anything
+func (t *TranspositionTable) Probe(hash uint64) (TableEntry, bool) {
+	if t == nil || t.entries == nil {
+		return TableEntry{}, false
+	}
+	i := hash % uint64(t.Size)
+	if !t.used[i] || t.entries[i].Hash != hash {
+		return TableEntry{}, false
+	}
+	return t.entries[i], true
+}
+
+func (t *TranspositionTable) Store(e TableEntry) {
+	if t == nil {
+		return
+	}
+	// agents that never search deep enough to use the table don't pay for it
+	if t.entries == nil {
+		t.entries = make([]TableEntry, t.Size)
+		t.used = make([]bool, t.Size)
+	}
+	i := e.Hash % uint64(t.Size)
+	t.entries[i] = e
+	t.used[i] = true
+}
+
+func (t *TranspositionTable) Clear() {
+	if t == nil {
+		return
+	}
+	t.entries = nil
+	t.used = nil
+}
+
+// boundFor classifies the value of a finished search with the original window
+func boundFor(value int, alpha, beta int) Bound {
+	if value <= alpha {
+		return UPPER_BOUND
+	} else if value >= beta {
+		return LOWER_BOUND
+	}
+	return EXACT
+}
+
+// nthMove orders the moves with the move from the table first, at index -1. An empty
+// first move means there is none. It returns false for the first move's place in the
+// list, since it has already been searched
+func nthMove(moves []core.GameMove, i int, first core.GameMove) (core.GameMove, bool) {
+	if i == -1 {
+		return first, len(first.Coords) > 0
+	}
+	if len(first.Coords) > 0 && sameCoords(moves[i], first) {
+		return moves[i], false
+	}
+	return moves[i], true
+}
+
+func sameCoords(a, b core.GameMove) bool {
+	if len(a.Coords) != len(b.Coords) {
+		return false
+	}
+	for i := range a.Coords {
+		if a.Coords[i] != b.Coords[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,422 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// Bot is a contestant in a tournament
+type Bot struct {
+	Name string
+	// makes a fresh player for each game, since players keep state between moves and
+	// games are played at the same time
+	New func(seed int64) core.Player
+}
+
+// BotConfig describes a bot in a tournament file, see NewBot
+type BotConfig struct {
+	Name string
+	// "agent", "mcts" or "random"
+	Kind string
+	// preset of an agent, like "Hard". the other settings change it further
+	Difficulty string
+	// search depth of an agent, zero to keep the default
+	Depth int
+	// games an MCTS player plays out for each move, zero to keep the default
+	Iterations int
+	// whether an MCTS player uses guided rollouts
+	Guided bool
+	// file with the evaluation weights of an agent or an MCTS player, see ReadWeights
+	Weights string
+}
+
+func NewBot(config BotConfig, rules core.Rules) (Bot, error) {
+	weights := DEFAULT_WEIGHTS
+	if config.Weights != "" {
+		w, err := LoadWeights(config.Weights)
+		if err != nil {
+			return Bot{}, err
+		}
+		weights = w
+	}
+	bot := Bot{Name: config.Name}
+
+	if config.Kind == "agent" {
+		difficulty := EXPERT
+		if config.Difficulty != "" {
+			d, ok := ParseDifficulty(config.Difficulty)
+			if !ok {
+				return Bot{}, fmt.Errorf("%s: unknown difficulty %q", config.Name, config.Difficulty)
+			}
+			difficulty = d
+		}
+		bot.New = func(seed int64) core.Player {
+			a := NewAgentWithRules(rules, seed, seed)
+			a.SetDifficulty(difficulty)
+			if config.Depth > 0 {
+				a.Depth = config.Depth
+			}
+			a.Weights = weights
+			return a
+		}
+	} else if config.Kind == "mcts" {
+		bot.New = func(seed int64) core.Player {
+			p := NewMCTSPlayer(seed)
+			if config.Iterations > 0 {
+				p.Iterations = config.Iterations
+			}
+			p.Guided = config.Guided
+			p.Weights = weights
+			return p
+		}
+	} else if config.Kind == "random" {
+		bot.New = func(seed int64) core.Player {
+			return NewRandomPlayer(seed)
+		}
+	} else {
+		return Bot{}, fmt.Errorf("%s: unknown kind of bot %q", config.Name, config.Kind)
+	}
+	return bot, nil
+}
+
+func ParseDifficulty(name string) (Difficulty, bool) {
+	for _, d := range DIFFICULTIES {
+		if strings.EqualFold(d.String(), name) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+type TournamentConfig struct {
+	Rules core.Rules
+	// every pair of bots plays each of these maps twice, once from each side
+	Seeds    []int64
+	MaxTurns int
+	// games played at the same time
+	Workers int
+	// called after each game, from the goroutine that played it
+	OnGame func(TournamentGame)
+}
+
+// TournamentGame is the result of one game of a tournament
+type TournamentGame struct {
+	// indices of the bots in the tournament
+	East, West int
+	Seed       int64
+	Result     SelfPlayResult
+}
+
+// Score is the points the bot got from the game, with a draw worth half
+func (g TournamentGame) Score(bot int) float64 {
+	side := core.EAST
+	if bot == g.West {
+		side = core.WEST
+	}
+	if g.Result.Winner == side {
+		return 1
+	} else if g.Result.Winner == 0 {
+		return 0.5
+	}
+	return 0
+}
+
+// Margin is the bot's health minus its opponent's at the end of the game
+func (g TournamentGame) Margin(bot int) int {
+	if bot == g.West {
+		return -g.Result.HealthMargin
+	}
+	return g.Result.HealthMargin
+}
+
+type TournamentResult struct {
+	Bots []string
+	// in the order they were scheduled, whatever order they finished in
+	Games []TournamentGame
+}
+
+// RunTournament plays a round robin between the bots. If the context is done it stops
+// early, returning the games that finished along with the context's error
+func RunTournament(ctx context.Context, config TournamentConfig, bots []Bot) (*TournamentResult, error) {
+	result := &TournamentResult{}
+	for _, b := range bots {
+		result.Bots = append(result.Bots, b.Name)
+	}
+	for i := range bots {
+		for j := i + 1; j < len(bots); j++ {
+			for _, seed := range config.Seeds {
+				result.Games = append(result.Games, TournamentGame{East: i, West: j, Seed: seed})
+				result.Games = append(result.Games, TournamentGame{East: j, West: i, Seed: seed})
+			}
+		}
+	}
+
+	workers := config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var next int
+	var mu sync.Mutex
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				i := next
+				next += 1
+				mu.Unlock()
+				if i >= len(result.Games) || ctx.Err() != nil {
+					return
+				}
+
+				g := &result.Games[i]
+				// the sides get different seeds so that a bot playing itself doesn't play
+				// the same moves as its opponent
+				east := bots[g.East].New(g.Seed)
+				west := bots[g.West].New(g.Seed + 1)
+				res, err := PlayGame(ctx, config.Rules, g.Seed, east, west, config.MaxTurns)
+				if err == nil {
+					err = ctx.Err()
+				}
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				g.Result = res
+				if config.OnGame != nil {
+					config.OnGame(*g)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	finished := make([]TournamentGame, 0, len(result.Games))
+	for _, g := range result.Games {
+		if g.Result.Record != nil {
+			finished = append(finished, g)
+		}
+	}
+	result.Games = finished
+	return result, firstErr
+}
+
+// PairingStats adds up the games between two bots, from the first one's point of view
+type PairingStats struct {
+	Wins, Losses, Draws int
+	// health margins added up over the games
+	TotalMargin int
+}
+
+func (s PairingStats) Games() int {
+	return s.Wins + s.Losses + s.Draws
+}
+
+func (s PairingStats) AverageMargin() float64 {
+	if s.Games() == 0 {
+		return 0
+	}
+	return float64(s.TotalMargin) / float64(s.Games())
+}
+
+// Pairings returns the stats of every bot against every other bot, indexed by the bots
+func (r *TournamentResult) Pairings() [][]PairingStats {
+	stats := make([][]PairingStats, len(r.Bots))
+	for i := range stats {
+		stats[i] = make([]PairingStats, len(r.Bots))
+	}
+	for _, g := range r.Games {
+		for _, bot := range []int{g.East, g.West} {
+			opp := g.West
+			if bot == g.West {
+				opp = g.East
+			}
+			s := &stats[bot][opp]
+			if score := g.Score(bot); score == 1 {
+				s.Wins += 1
+			} else if score == 0 {
+				s.Losses += 1
+			} else {
+				s.Draws += 1
+			}
+			s.TotalMargin += g.Margin(bot)
+		}
+	}
+	return stats
+}
+
+// Rating is a bot's Elo, with the bounds of its 95% confidence interval
+type Rating struct {
+	Elo, Low, High float64
+}
+
+// ELO_BOOTSTRAP_SAMPLES is how many times the games are resampled to find the confidence
+// intervals of the ratings
+const ELO_BOOTSTRAP_SAMPLES = 200
+
+// Ratings estimates the Elo of each bot from all of the games, with the average bot at 0.
+// The confidence intervals come from rating games drawn at random from the tournament with
+// replacement, over and over
+func (r *TournamentResult) Ratings() []Rating {
+	elo := eloFromGames(len(r.Bots), r.Games)
+	ratings := make([]Rating, len(r.Bots))
+	for i := range ratings {
+		ratings[i].Elo = elo[i]
+	}
+	if len(r.Games) == 0 {
+		return ratings
+	}
+
+	random := rand.New(rand.NewSource(1))
+	samples := make([][]float64, len(r.Bots))
+	resampled := make([]TournamentGame, len(r.Games))
+	for s := 0; s < ELO_BOOTSTRAP_SAMPLES; s++ {
+		for i := range resampled {
+			resampled[i] = r.Games[random.Intn(len(r.Games))]
+		}
+		for i, e := range eloFromGames(len(r.Bots), resampled) {
+			samples[i] = append(samples[i], e)
+		}
+	}
+	for i := range ratings {
+		sort.Float64s(samples[i])
+		ratings[i].Low = samples[i][ELO_BOOTSTRAP_SAMPLES*25/1000]
+		ratings[i].High = samples[i][ELO_BOOTSTRAP_SAMPLES*975/1000]
+	}
+	return ratings
+}
+
+// eloFromGames fits a Bradley-Terry model to the games, counting draws as half a win for
+// each bot. Every pair of bots also gets one made up draw, which keeps a bot that never
+// won or never lost from going off to infinity
+func eloFromGames(bots int, games []TournamentGame) []float64 {
+	wins := make([]float64, bots)
+	played := make([][]float64, bots)
+	for i := range played {
+		played[i] = make([]float64, bots)
+		for j := range played[i] {
+			if i != j {
+				played[i][j] = 1
+				wins[i] += 0.5
+			}
+		}
+	}
+	for _, g := range games {
+		wins[g.East] += g.Score(g.East)
+		wins[g.West] += g.Score(g.West)
+		played[g.East][g.West] += 1
+		played[g.West][g.East] += 1
+	}
+
+	// the usual fixed point iteration for the strengths
+	strength := make([]float64, bots)
+	for i := range strength {
+		strength[i] = 1
+	}
+	for iter := 0; iter < 1000; iter++ {
+		change := 0.0
+		for i := range strength {
+			denom := 0.0
+			for j := range strength {
+				if i != j {
+					denom += played[i][j] / (strength[i] + strength[j])
+				}
+			}
+			if denom == 0 {
+				continue
+			}
+			s := wins[i] / denom
+			change = math.Max(change, math.Abs(s-strength[i])/strength[i])
+			strength[i] = s
+		}
+		if change < 1e-9 {
+			break
+		}
+	}
+
+	elo := make([]float64, bots)
+	mean := 0.0
+	for i, s := range strength {
+		elo[i] = 400 * math.Log10(s)
+		mean += elo[i]
+	}
+	for i := range elo {
+		elo[i] -= mean / float64(bots)
+	}
+	return elo
+}
+
+// Write prints the results as tables: wins, losses and draws of each bot against each
+// other bot, the average health margins, and the ratings
+func (r *TournamentResult) Write(w io.Writer) error {
+	pairings := r.Pairings()
+	width := 8
+	for _, name := range r.Bots {
+		if len(name)+2 > width {
+			width = len(name) + 2
+		}
+	}
+	table := func(title string, cell func(s PairingStats) string) string {
+		out := title + "\n" + fmt.Sprintf("%-*s", width, "")
+		for _, name := range r.Bots {
+			out += fmt.Sprintf("%*s", width, name)
+		}
+		out += "\n"
+		for i, name := range r.Bots {
+			out += fmt.Sprintf("%-*s", width, name)
+			for j := range r.Bots {
+				if i == j {
+					out += fmt.Sprintf("%*s", width, "-")
+				} else {
+					out += fmt.Sprintf("%*s", width, cell(pairings[i][j]))
+				}
+			}
+			out += "\n"
+		}
+		return out + "\n"
+	}
+
+	out := table("Wins-losses-draws of each row against each column", func(s PairingStats) string {
+		return fmt.Sprintf("%d-%d-%d", s.Wins, s.Losses, s.Draws)
+	})
+	out += table("Average health margin of each row against each column", func(s PairingStats) string {
+		return fmt.Sprintf("%+.1f", s.AverageMargin())
+	})
+
+	ratings := r.Ratings()
+	order := make([]int, len(r.Bots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return ratings[order[a]].Elo > ratings[order[b]].Elo
+	})
+	out += "Elo, with 95% confidence intervals\n"
+	for _, i := range order {
+		score, games := 0.0, 0
+		for _, g := range r.Games {
+			if g.East == i || g.West == i {
+				score += g.Score(i)
+				games += 1
+			}
+		}
+		out += fmt.Sprintf("%-*s %+7.0f  [%+.0f, %+.0f]  %.1f/%d\n", width, r.Bots[i], ratings[i].Elo, ratings[i].Low, ratings[i].High, score, games)
+	}
+	_, err := io.WriteString(w, out)
+	return err
+}
@@ -0,0 +1,234 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"os"
+	"sync"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// TuneConfig controls Tune. The tuner uses SPSA: every iteration it nudges all the weights
+// at once in a random direction, plays the weights nudged one way against the weights
+// nudged the other way, and moves the weights towards whichever side won more
+type TuneConfig struct {
+	Rules core.Rules
+	// the features whose weights are tuned. the rest keep their starting weights. the
+	// default leaves out health, since scaling every weight at once doesn't change how the
+	// agent plays
+	Features []Feature
+	// games played in each iteration are twice this, since each map is played from both
+	// sides
+	Pairs int
+	// search depth of the agents, kept low so that the games are fast
+	Depth    int
+	MaxTurns int
+	// games played at the same time
+	Workers int
+	// how far the weights are nudged to compare them, at the start
+	Perturbation float64
+	// how far the weights move after each iteration, at the start. a 100% score moves them
+	// by StepSize/(2*Perturbation)
+	StepSize float64
+	// iterations before the steps start getting smaller, which keeps the early steps from
+	// being too wild
+	Stability float64
+}
+
+var DEFAULT_TUNE_CONFIG = TuneConfig{
+	Rules:        core.DefaultRules(),
+	Features:     []Feature{BOARD_POWER_FEATURE, RESERVE_POWER_FEATURE, DISTANCE_TO_GOAL_FEATURE, PENDING_BUFFS_FEATURE, THREATENED_CREATURES_FEATURE},
+	Pairs:        8,
+	Depth:        1,
+	MaxTurns:     100,
+	Workers:      1,
+	Perturbation: 0.2,
+	StepSize:     0.5,
+	Stability:    50,
+}
+
+// the usual SPSA decay rates of the step size and the perturbation
+const (
+	SPSA_ALPHA = 0.602
+	SPSA_GAMMA = 0.101
+)
+
+// TuneState is everything needed to pick the tuning up where it left off. It's saved after
+// every iteration, see SaveTuneState
+type TuneState struct {
+	// the seed of the tuning run. each iteration gets its own random numbers from it, so a
+	// run that is resumed plays the same games as one that wasn't stopped
+	Seed      int64
+	Iteration int
+	Weights   Weights
+	History   []TuneIteration
+}
+
+// TuneIteration records how an iteration went
+type TuneIteration struct {
+	// the score of the weights nudged up out of the games played, with a draw worth half
+	Score   float64
+	Games   int
+	Weights Weights
+}
+
+func NewTuneState(seed int64, start Weights) *TuneState {
+	return &TuneState{Seed: seed, Weights: start}
+}
+
+// Tune runs iterations until there have been the given number in total, calling checkpoint
+// after each one. It stops early without an error when the context is done, keeping the
+// state of the last iteration that finished
+func Tune(ctx context.Context, config TuneConfig, state *TuneState, iterations int, checkpoint func(*TuneState) error) error {
+	for state.Iteration < iterations {
+		if ctx.Err() != nil {
+			return nil
+		}
+		random := rand.New(rand.NewSource(state.Seed + int64(state.Iteration)))
+		k := float64(state.Iteration)
+		step := config.StepSize / math.Pow(k+1+config.Stability, SPSA_ALPHA)
+		perturbation := config.Perturbation / math.Pow(k+1, SPSA_GAMMA)
+
+		delta := Weights{}
+		plus, minus := state.Weights, state.Weights
+		for _, f := range config.Features {
+			delta[f] = 1
+			if random.Intn(2) == 0 {
+				delta[f] = -1
+			}
+			plus[f] += perturbation * delta[f]
+			minus[f] -= perturbation * delta[f]
+		}
+
+		seeds := make([]int64, config.Pairs)
+		for i := range seeds {
+			seeds[i] = random.Int63()
+		}
+		score, games, ok := playPairs(ctx, config, plus, minus, seeds)
+		if !ok {
+			return nil
+		}
+
+		// the gradient along each feature is the same score difference, with the sign of
+		// the nudge
+		diff := 2*score/float64(games) - 1
+		for _, f := range config.Features {
+			state.Weights[f] += step * diff / (2 * perturbation * delta[f])
+		}
+		state.Iteration += 1
+		state.History = append(state.History, TuneIteration{Score: score, Games: games, Weights: state.Weights})
+		if checkpoint != nil {
+			if err := checkpoint(state); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// playPairs plays the first weights against the second on each map from both sides and
+// returns the score of the first. It returns false if the context was done before all of
+// the games finished
+func playPairs(ctx context.Context, config TuneConfig, first, second Weights, seeds []int64) (float64, int, bool) {
+	type game struct {
+		seed int64
+		side core.Alignment
+	}
+	games := make(chan game)
+	scores := make(chan float64)
+	workers := config.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range games {
+				a := NewAgentWithRules(config.Rules, g.seed, g.seed)
+				b := NewAgentWithRules(config.Rules, g.seed, g.seed+1)
+				a.Depth, b.Depth = config.Depth, config.Depth
+				a.Weights, b.Weights = first, second
+				east, west := a, b
+				if g.side == core.WEST {
+					east, west = b, a
+				}
+				res, err := PlayGame(ctx, config.Rules, g.seed, east, west, config.MaxTurns)
+				score := 0.5
+				if err != nil || ctx.Err() != nil {
+					score = -1
+				} else if res.Winner == g.side {
+					score = 1
+				} else if res.Winner == g.side.Opposite() {
+					score = 0
+				}
+				scores <- score
+			}
+		}()
+	}
+	go func() {
+		defer close(games)
+		for _, seed := range seeds {
+			for _, side := range []core.Alignment{core.EAST, core.WEST} {
+				select {
+				case games <- game{seed, side}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(scores)
+	}()
+
+	total, played, ok := 0.0, 0, true
+	for s := range scores {
+		if s < 0 {
+			ok = false
+			continue
+		}
+		total += s
+		played += 1
+	}
+	return total, played, ok && played == 2*len(seeds)
+}
+
+func LoadTuneState(path string) (*TuneState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	state := &TuneState{}
+	if err := json.NewDecoder(f).Decode(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// SaveTuneState writes the state to a temporary file first, so that a run killed while
+// saving still has its last checkpoint
+func SaveTuneState(path string, state *TuneState) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(state); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,98 @@
+// Command tournament plays bots against each other without a display and reports how they
+// did, see ai.RunTournament. The bots come from a JSON file with a list of ai.BotConfig:
+//
+//	[
+//	  {"Name": "hard", "Kind": "agent", "Difficulty": "Hard"},
+//	  {"Name": "tuned", "Kind": "agent", "Difficulty": "Hard", "Weights": "weights.json"},
+//	  {"Name": "mcts", "Kind": "mcts", "Iterations": 2000}
+//	]
+//
+// Without a file the four difficulties play each other. Stopping it with ctrl-c still
+// reports the games that finished.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+
+	"github.com/prizelobby/reverset-raiders/ai"
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+func main() {
+	configFile := flag.String("bots", "", "JSON file with the bots to play")
+	seeds := flag.Int("seeds", 10, "maps each pair of bots plays, once from each side")
+	firstSeed := flag.Int64("first-seed", 1, "seed of the first map, the others follow it")
+	maxTurns := flag.Int("max-turns", 200, "turns before a game counts as a draw")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at the same time")
+	flag.Parse()
+
+	rules := core.DefaultRules()
+	configs := make([]ai.BotConfig, 0)
+	if *configFile != "" {
+		f, err := os.Open(*configFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = json.NewDecoder(f).Decode(&configs)
+		f.Close()
+		if err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, d := range ai.DIFFICULTIES {
+			configs = append(configs, ai.BotConfig{Name: d.String(), Kind: "agent", Difficulty: d.String()})
+		}
+	}
+	if len(configs) < 2 {
+		log.Fatal("need at least two bots")
+	}
+	bots := make([]ai.Bot, 0, len(configs))
+	for _, c := range configs {
+		bot, err := ai.NewBot(c, rules)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bots = append(bots, bot)
+	}
+
+	config := ai.TournamentConfig{
+		Rules:    rules,
+		MaxTurns: *maxTurns,
+		Workers:  *workers,
+	}
+	for i := 0; i < *seeds; i++ {
+		config.Seeds = append(config.Seeds, *firstSeed+int64(i))
+	}
+	total := len(bots) * (len(bots) - 1) * len(config.Seeds)
+	config.OnGame = func(g ai.TournamentGame) {
+		// games finish on several goroutines, but the log writes one line at a time
+		log.Printf("%s (east) vs %s (west) on map %d: %s after %d turns", bots[g.East].Name, bots[g.West].Name, g.Seed, winner(g, bots), g.Result.Turns)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	log.Printf("playing %d games", total)
+	result, err := ai.RunTournament(ctx, config, bots)
+	if err != nil {
+		log.Printf("stopped after %d of %d games: %v", len(result.Games), total, err)
+	}
+	if err := result.Write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func winner(g ai.TournamentGame, bots []ai.Bot) string {
+	if g.Result.Winner == core.EAST {
+		return bots[g.East].Name + " won"
+	} else if g.Result.Winner == core.WEST {
+		return bots[g.West].Name + " won"
+	}
+	return "draw"
+}
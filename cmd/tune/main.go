@@ -0,0 +1,84 @@
+// Command tune improves the weights of the AI's evaluation by self-play, see ai.Tune. It
+// doesn't need a display, so it can run for hours on a server:
+//
+//	go run ./cmd/tune -iterations 500 -checkpoint tune.json -out weights.json
+//
+// Progress is saved to the checkpoint after every iteration, and running the same command
+// again picks up from there. The game loads the weights with its -weights flag.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/prizelobby/reverset-raiders/ai"
+)
+
+func main() {
+	iterations := flag.Int("iterations", 200, "iterations to run in total, counting the ones in the checkpoint")
+	pairs := flag.Int("pairs", ai.DEFAULT_TUNE_CONFIG.Pairs, "maps played from both sides in each iteration")
+	depth := flag.Int("depth", ai.DEFAULT_TUNE_CONFIG.Depth, "search depth of the agents")
+	maxTurns := flag.Int("max-turns", ai.DEFAULT_TUNE_CONFIG.MaxTurns, "turns before a game counts as a draw")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at the same time")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of a new run")
+	start := flag.String("start", "", "weights file to start a new run from, instead of the default weights")
+	checkpoint := flag.String("checkpoint", "tune.json", "file the progress is saved to and resumed from")
+	out := flag.String("out", "weights.json", "file the tuned weights are written to")
+	flag.Parse()
+
+	config := ai.DEFAULT_TUNE_CONFIG
+	config.Pairs = *pairs
+	config.Depth = *depth
+	config.MaxTurns = *maxTurns
+	config.Workers = *workers
+	if config.Pairs < 1 {
+		log.Fatal("need at least one pair of games for each iteration")
+	}
+
+	state, err := ai.LoadTuneState(*checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		weights := ai.DEFAULT_WEIGHTS
+		if *start != "" {
+			if weights, err = ai.LoadWeights(*start); err != nil {
+				log.Fatal(err)
+			}
+		}
+		state = ai.NewTuneState(*seed, weights)
+	} else if err != nil {
+		log.Fatal(err)
+	} else {
+		log.Printf("resuming from iteration %d of %s", state.Iteration, *checkpoint)
+	}
+
+	// stopping with ctrl-c still writes out the weights of the last finished iteration
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = ai.Tune(ctx, config, state, *iterations, func(s *ai.TuneState) error {
+		last := s.History[len(s.History)-1]
+		log.Printf("iteration %d: scored %.1f/%d, weights %v", s.Iteration, last.Score, last.Games, s.Weights)
+		return ai.SaveTuneState(*checkpoint, s)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := state.Weights.Write(f); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote the weights after %d iterations to %s", state.Iteration, *out)
+}
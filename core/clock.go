@@ -0,0 +1,107 @@
+package core
+
+import "time"
+
+// a zero duration means that there is no limit of that kind
+type TimeControl struct {
+	PerMove   time.Duration
+	Total     time.Duration
+	Increment time.Duration
+}
+
+var UNTIMED = TimeControl{}
+var BLITZ = TimeControl{PerMove: 30 * time.Second, Total: 3 * time.Minute, Increment: 2 * time.Second}
+var STANDARD = TimeControl{PerMove: time.Minute, Total: 10 * time.Minute, Increment: 5 * time.Second}
+
+func (tc TimeControl) IsUntimed() bool {
+	return tc.PerMove == 0 && tc.Total == 0
+}
+
+type Clock struct {
+	Control       TimeControl
+	EastRemaining time.Duration
+	WestRemaining time.Duration
+	// the side whose clock is currently running, or 0 if the clock is stopped
+	Running   Alignment
+	TurnStart time.Time
+}
+
+func NewClock(tc TimeControl) *Clock {
+	return &Clock{
+		Control:       tc,
+		EastRemaining: tc.Total,
+		WestRemaining: tc.Total,
+	}
+}
+
+func (c *Clock) remaining(a Alignment) *time.Duration {
+	if a == EAST {
+		return &c.EastRemaining
+	}
+	return &c.WestRemaining
+}
+
+func (c *Clock) Start(a Alignment, now time.Time) {
+	c.Running = a
+	c.TurnStart = now
+}
+
+// Stop charges the time spent by the running side to its total and adds the increment.
+// It returns true if the side ran out of time during this move.
+func (c *Clock) Stop(now time.Time) bool {
+	if c.Running == 0 {
+		return false
+	}
+	flagged := c.IsFlagged(c.Running, now)
+	r := c.remaining(c.Running)
+	*r -= now.Sub(c.TurnStart)
+	if !flagged {
+		*r += c.Control.Increment
+	}
+	c.Running = 0
+	return flagged
+}
+
+// TotalLeft is the time left on the side's total budget, counting the move in progress.
+func (c *Clock) TotalLeft(a Alignment, now time.Time) time.Duration {
+	left := *c.remaining(a)
+	if c.Running == a {
+		left -= now.Sub(c.TurnStart)
+	}
+	return left
+}
+
+// MoveTimeLeft is how long the side can still think about the current move before
+// losing on time, taking both the per move and the total limits into account.
+// It returns -1 if the clock is untimed.
+func (c *Clock) MoveTimeLeft(a Alignment, now time.Time) time.Duration {
+	if c.Control.IsUntimed() {
+		return -1
+	}
+	var elapsed time.Duration
+	if c.Running == a {
+		elapsed = now.Sub(c.TurnStart)
+	}
+
+	// the total can already be used up, so a negative value is still a limit
+	var left time.Duration
+	limited := false
+	if c.Control.Total > 0 {
+		left = *c.remaining(a) - elapsed
+		limited = true
+	}
+	if c.Control.PerMove > 0 {
+		perMove := c.Control.PerMove - elapsed
+		if !limited || perMove < left {
+			left = perMove
+		}
+	}
+	if left < 0 {
+		left = 0
+	}
+	return left
+}
+
+func (c *Clock) IsFlagged(a Alignment, now time.Time) bool {
+	return !c.Control.IsUntimed() && c.MoveTimeLeft(a, now) <= 0
+}
@@ -0,0 +1,81 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Now()
+	at := func(s float64) time.Time {
+		return start.Add(time.Duration(s * float64(time.Second)))
+	}
+
+	// total only
+	clock := core.NewClock(core.TimeControl{Total: 10 * time.Second, Increment: 2 * time.Second})
+	clock.Start(core.EAST, at(0))
+	if clock.Stop(at(3)) {
+		t.Fatal("flagged with time left on the total")
+	}
+	if clock.EastRemaining != 9*time.Second || clock.WestRemaining != 10*time.Second {
+		t.Fatalf("east has %s and west %s left, expected 9s and 10s", clock.EastRemaining, clock.WestRemaining)
+	}
+	clock.Start(core.EAST, at(10))
+	if left := clock.MoveTimeLeft(core.EAST, at(12)); left != 7*time.Second {
+		t.Fatalf("%s left for the move, expected 7s", left)
+	}
+	if clock.IsFlagged(core.EAST, at(18)) || !clock.IsFlagged(core.EAST, at(19)) {
+		t.Fatal("should flag exactly when the total runs out")
+	}
+	if !clock.Stop(at(20)) || clock.EastRemaining != -time.Second {
+		t.Fatalf("running out of time should flag without an increment, %s left", clock.EastRemaining)
+	}
+
+	// per move only
+	clock = core.NewClock(core.TimeControl{PerMove: 5 * time.Second})
+	for i := 0; i < 3; i++ {
+		clock.Start(core.WEST, at(float64(10*i)))
+		if clock.Stop(at(float64(10*i) + 4)) {
+			t.Fatal("flagged within the per move limit")
+		}
+	}
+	clock.Start(core.WEST, at(30))
+	if clock.IsFlagged(core.WEST, at(34)) || !clock.IsFlagged(core.WEST, at(35)) {
+		t.Fatal("should flag exactly when the move's time runs out")
+	}
+	if clock.IsFlagged(core.EAST, at(35)) {
+		t.Fatal("flagged the side that isn't moving")
+	}
+	if !clock.Stop(at(36)) {
+		t.Fatal("stopping after the per move limit should flag")
+	}
+
+	// both, with the total running out before the move's time
+	clock = core.NewClock(core.BLITZ)
+	clock.EastRemaining = time.Second
+	clock.Start(core.EAST, at(0))
+	if left := clock.MoveTimeLeft(core.EAST, at(0.5)); left != 500*time.Millisecond {
+		t.Fatalf("%s left for the move, expected 500ms", left)
+	}
+	if left := clock.MoveTimeLeft(core.EAST, at(5)); left != 0 || !clock.IsFlagged(core.EAST, at(5)) {
+		t.Fatalf("%s left for the move after the total ran out", left)
+	}
+	if !clock.Stop(at(5)) {
+		t.Fatal("stopping after the total ran out should flag")
+	}
+	// and the move's time running out first
+	clock.WestRemaining = time.Minute
+	clock.Start(core.WEST, at(10))
+	if left := clock.MoveTimeLeft(core.WEST, at(10)); left != core.BLITZ.PerMove {
+		t.Fatalf("%s left for the move, expected %s", left, core.BLITZ.PerMove)
+	}
+	if clock.Stop(at(20)) || clock.WestRemaining != 52*time.Second {
+		t.Fatalf("west has %s left, expected 52s", clock.WestRemaining)
+	}
+	clock.Start(core.WEST, at(30))
+	if !clock.Stop(at(61)) {
+		t.Fatal("stopping after the per move limit should flag")
+	}
+}
@@ -46,6 +46,12 @@ type Creature struct {
 	Color     CreatureColor
 	Species   Species
 	Removed   bool
+	// the creature loses nothing in its next collision
+	Shielded bool
+	// absorbs collision damage before any power is lost, see Rules.Armor
+	Armor int
+	// one point for every enemy killed in a collision, see Level
+	Experience int
 }
 
 func (c *Creature) Name() string {
@@ -70,7 +76,17 @@ func (c *Creature) ApplyEffect(e Effect) bool {
 	}
 
 	if (e.ColorCondition == NO_COLOR || e.ColorCondition == c.Color) && (e.SpeciesCondition == NO_SPECIES || e.SpeciesCondition == c.Species) {
-		c.Power += e.Value
+		if e.Kind == ARMOR_EFFECT {
+			c.Armor += e.Value
+		} else if e.Kind == SHIELD_EFFECT {
+			// shields don't stack
+			if c.Shielded {
+				return false
+			}
+			c.Shielded = true
+		} else {
+			c.Power += e.Value
+		}
 		return true
 	}
 	return false
@@ -128,3 +144,26 @@ func GetInitialRandomCreatures(a Alignment, random *rand.Rand) []*Creature {
 
 	return creatures
 }
+
+// MirroredCreatures gives the WEST side the same creatures as the EAST side, in the
+// matching positions for the map symmetry
+func MirroredCreatures(east []*Creature, symmetry MapSymmetry) []*Creature {
+	creatures := make([]*Creature, 0, len(east))
+	for _, ec := range east {
+		// creatures start off the map, where the rows are all even
+		y := ec.Y
+		if symmetry == POINT_SYMMETRY {
+			y = 2*(MAP_HEIGHT-1) - ec.Y
+		}
+		creatures = append(creatures, &Creature{
+			X:         MAP_WIDTH - 1 - ec.X,
+			Y:         y,
+			Alignment: WEST,
+			Id:        ec.Id,
+			Power:     ec.Power,
+			Color:     ec.Color,
+			Species:   ec.Species,
+		})
+	}
+	return creatures
+}
@@ -13,7 +13,19 @@ const (
 	ALL
 )
 
+// EffectKind is what an effect gives the creatures it applies to
+type EffectKind int
+
+const (
+	POWER_EFFECT EffectKind = iota
+	// armor absorbs damage in collisions before any power is lost
+	ARMOR_EFFECT
+	// a shield that breaks on the next collision, like the SHIELD power
+	SHIELD_EFFECT
+)
+
 type Effect struct {
+	Kind             EffectKind
 	X                int
 	Y                int
 	Targets          TargetType
@@ -23,6 +35,15 @@ type Effect struct {
 }
 
 func (e Effect) String() string {
+	if e.Kind == ARMOR_EFFECT {
+		return e.describe(fmt.Sprintf("armor +%d", e.Value))
+	} else if e.Kind == SHIELD_EFFECT {
+		return e.describe("shield")
+	}
+	return e.describe(fmt.Sprintf("+%d", e.Value))
+}
+
+func (e Effect) describe(bonus string) string {
 	target := ""
 	if e.Targets == ALL {
 		target = "all"
@@ -31,13 +52,13 @@ func (e Effect) String() string {
 	}
 
 	if e.SpeciesCondition == NO_SPECIES && e.ColorCondition == NO_COLOR {
-		return fmt.Sprintf("%s +%d", target, e.Value)
+		return fmt.Sprintf("%s %s", target, bonus)
 	}
 	if e.SpeciesCondition != NO_SPECIES {
-		return fmt.Sprintf("%s %s +%d", target, e.SpeciesCondition, e.Value)
+		return fmt.Sprintf("%s %s %s", target, e.SpeciesCondition, bonus)
 	}
 	if e.ColorCondition != NO_COLOR {
-		return fmt.Sprintf("%s %s +%d", target, e.ColorCondition, e.Value)
+		return fmt.Sprintf("%s %s %s", target, e.ColorCondition, bonus)
 	}
 
 	return ""
@@ -82,3 +103,18 @@ func RandomEffect(x, y int, random *rand.Rand) Effect {
 		Value:            value,
 	}
 }
+
+// ArmorEffect turns some of the rolled power effects into armor or shield effects when
+// the armor rules are on. Armor is worth about half as much as power since it only
+// helps in collisions.
+func ArmorEffect(e Effect, random *rand.Rand) Effect {
+	roll := random.Intn(10)
+	if roll < 3 {
+		e.Kind = ARMOR_EFFECT
+		e.Value = (e.Value + 1) / 2
+	} else if roll == 3 {
+		e.Kind = SHIELD_EFFECT
+		e.Value = 0
+	}
+	return e
+}
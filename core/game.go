@@ -5,63 +5,185 @@ import (
 	"time"
 )
 
+// GameMove lists the tiles reversed in a turn. With the reserve swap rule, a move can also
+// bring reserve creatures to the front of their row, each one taking the place of a reversal.
+// Altogether there are at most Game.ReversalsAllowed of them
 type GameMove struct {
-	First  MapCoord
-	Second MapCoord
+	Coords []MapCoord
+	// indices of the creatures in the list of the moving side
+	Swaps []int
+	// a power used along with the reversals, NO_POWER for none
+	Power PowerMove
+}
+
+func NewGameMove(coords ...MapCoord) GameMove {
+	return GameMove{Coords: coords}
+}
+
+func (m GameMove) Contains(c MapCoord) bool {
+	for _, mc := range m.Coords {
+		if mc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// With returns a copy of the move with one more reversal
+func (m GameMove) With(c MapCoord) GameMove {
+	coords := make([]MapCoord, len(m.Coords), len(m.Coords)+1)
+	copy(coords, m.Coords)
+	return GameMove{Coords: append(coords, c), Swaps: m.Swaps, Power: m.Power}
+}
+
+// WithSwap returns a copy of the move that also brings a reserve creature to the front
+func (m GameMove) WithSwap(creature int) GameMove {
+	swaps := make([]int, len(m.Swaps), len(m.Swaps)+1)
+	copy(swaps, m.Swaps)
+	return GameMove{Coords: m.Coords, Swaps: append(swaps, creature), Power: m.Power}
+}
+
+// Size is the number of reversals the move uses up
+func (m GameMove) Size() int {
+	return len(m.Coords) + len(m.Swaps)
 }
 
 type Game struct {
-	Map               *Map
-	EastCreatures     []*Creature
-	WestCreatures     []*Creature
-	EastHealth        int
-	WestHealth        int
-	CurrentTurn       Alignment
-	Rand              *rand.Rand
-	Seed              int64
-	AllCoords         []MapCoord
-	AllUncheckedMoves []GameMove
+	Map           *Map
+	EastCreatures []*Creature
+	WestCreatures []*Creature
+	EastHealth    int
+	WestHealth    int
+	CurrentTurn   Alignment
+	Rand          *rand.Rand
+	Seed          int64
+	AllCoords     []MapCoord
+	// moves reversing a single tile, for searches that build a turn up one reversal at a time
+	SingleMoves        []GameMove
+	EastUncheckedMoves []GameMove
+	WestUncheckedMoves []GameMove
+	Rules              Rules
+	// nil if the game is untimed
+	Clock *Clock
+	// each side's move counts as a turn, or each round in the simultaneous variant
+	TurnNumber int
+	// creatures of the opponent killed by each side
+	EastKills int
+	WestKills int
+	// the turn each power can be used again, indexed by Power
+	EastPowersReady [NUM_POWERS]int
+	WestPowersReady [NUM_POWERS]int
+	// Winner is 0 when the game is drawn or still going on
+	Winner Alignment
+	Result GameResult
 }
 
 func NewGameWithSeed(seed int64) *Game {
+	return NewGameWithRules(seed, DefaultRules())
+}
+
+func NewGameWithRules(seed int64, rules Rules) *Game {
 	s := rand.NewSource(seed)
 	random := rand.New(s)
 
-	allCoords := make([]MapCoord, 0, MAP_HEIGHT*MAP_WIDTH)
-	for i := 0; i < MAP_WIDTH; i++ {
-		for j := i % 2; j < 2*MAP_HEIGHT; j += 2 {
-			allCoords = append(allCoords, MapCoord{i, j})
-		}
+	m := NewSymmetricMap(random, rules.MapSymmetry, rules.MapShape)
+	if rules.Armor {
+		m.AddArmorEffects(random, rules.MapSymmetry)
 	}
+	allCoords := m.Coords()
 
-	moves := make([]GameMove, 0, len(allCoords)*len(allCoords))
-	for i := 0; i < len(allCoords); i++ {
-		ci := allCoords[i]
-		for j := i + 1; j < len(allCoords); j++ {
-			cj := allCoords[j]
-			moves = append(moves, GameMove{
-				First:  ci,
-				Second: cj,
-			})
+	singleMoves := make([]GameMove, 0, len(allCoords))
+	for _, c := range allCoords {
+		singleMoves = append(singleMoves, NewGameMove(c))
+	}
+
+	eastCreatures := GetInitialRandomCreatures(EAST, random)
+	var westCreatures []*Creature
+	if rules.MapSymmetry == NO_SYMMETRY {
+		westCreatures = GetInitialRandomCreatures(WEST, random)
+	} else {
+		westCreatures = MirroredCreatures(eastCreatures, rules.MapSymmetry)
+	}
+
+	g := &Game{
+		Map:           m,
+		EastCreatures: eastCreatures,
+		WestCreatures: westCreatures,
+		EastHealth:    STARTING_HEALTH + rules.EastHandicap.ExtraHealth,
+		WestHealth:    STARTING_HEALTH + rules.WestHandicap.ExtraHealth,
+		CurrentTurn:   rules.FirstPlayer(),
+		Rand:          random,
+		Seed:          seed,
+		AllCoords:     allCoords,
+		SingleMoves:   singleMoves,
+		Rules:         rules,
+	}
+	g.EastUncheckedMoves = AppendCombinations(nil, allCoords, GameMove{}, g.ReversalsAllowed(EAST))
+	if g.ReversalsAllowed(WEST) == g.ReversalsAllowed(EAST) {
+		g.WestUncheckedMoves = g.EastUncheckedMoves
+	} else {
+		g.WestUncheckedMoves = AppendCombinations(nil, allCoords, GameMove{}, g.ReversalsAllowed(WEST))
+	}
+	for _, c := range g.EastCreatures {
+		c.Power += rules.EastHandicap.ExtraPower
+	}
+	for _, c := range g.WestCreatures {
+		c.Power += rules.WestHandicap.ExtraPower
+	}
+	if !rules.TimeControl.IsUntimed() {
+		g.Clock = NewClock(rules.TimeControl)
+	}
+	return g
+}
+
+// Clone makes a copy of the game that can be changed without affecting the original
+func (g *Game) Clone() *Game {
+	c := *g
+	c.Map = &Map{
+		Tiles: make([][]*Tile, len(g.Map.Tiles)),
+		Shape: g.Map.Shape,
+	}
+	for i, column := range g.Map.Tiles {
+		c.Map.Tiles[i] = make([]*Tile, len(column))
+		for j, t := range column {
+			if t != nil {
+				tile := *t
+				c.Map.Tiles[i][j] = &tile
+			}
 		}
-		moves = append(moves, GameMove{
-			First:  ci,
-			Second: MapCoord{-1, -1},
-		})
 	}
+	c.EastCreatures = cloneCreatures(g.EastCreatures)
+	c.WestCreatures = cloneCreatures(g.WestCreatures)
+	// nothing draws from the game's random source after the setup, so the copy gets its own
+	c.Rand = rand.New(rand.NewSource(g.Seed + int64(g.TurnNumber)))
+	if g.Clock != nil {
+		clock := *g.Clock
+		c.Clock = &clock
+	}
+	return &c
+}
+
+func cloneCreatures(creatures []*Creature) []*Creature {
+	cloned := make([]*Creature, len(creatures))
+	for i, cr := range creatures {
+		c := *cr
+		cloned[i] = &c
+	}
+	return cloned
+}
 
-	return &Game{
-		Map:               NewMap(random),
-		EastCreatures:     GetInitialRandomCreatures(EAST, random),
-		WestCreatures:     GetInitialRandomCreatures(WEST, random),
-		EastHealth:        50,
-		WestHealth:        50,
-		CurrentTurn:       EAST,
-		Rand:              random,
-		Seed:              seed,
-		AllCoords:         allCoords,
-		AllUncheckedMoves: moves,
+// AppendCombinations appends every way of extending the prefix with up to size more
+// of the given coords, keeping the coords in order
+func AppendCombinations(moves []GameMove, coords []MapCoord, prefix GameMove, size int) []GameMove {
+	if size <= 0 {
+		return moves
 	}
+	for i, c := range coords {
+		m := prefix.With(c)
+		moves = AppendCombinations(moves, coords[i+1:], m, size-1)
+		moves = append(moves, m)
+	}
+	return moves
 }
 
 func NewGame() *Game {
@@ -79,8 +201,47 @@ const (
 	REVERSE_TILE
 	DEATH
 	GAME_OVER
+	SUDDEN_DEATH
+	WORLD_EVENT
+	CHANGE_EFFECT
+	SWAP_RESERVE
+	USE_POWER
+	FREEZE_TILE
+	SHIELD_CREATURE
+	BREAK_SHIELD
+	HEAL_BASE
+	MERGE
+	GAIN_EXPERIENCE
+	LEVEL_UP
+	UPDATE_ARMOR
 )
 
+type GameResult int
+
+const (
+	NO_RESULT GameResult = iota
+	HEALTH_DEPLETED
+	OUT_OF_TIME
+	ELIMINATION
+	TILE_MAJORITY
+	HEALTH_AFTER_TURNS
+)
+
+func (r GameResult) String() string {
+	if r == HEALTH_DEPLETED {
+		return "base destroyed"
+	} else if r == OUT_OF_TIME {
+		return "on time"
+	} else if r == ELIMINATION {
+		return "by elimination"
+	} else if r == TILE_MAJORITY {
+		return "by holding the most reversed tiles"
+	} else if r == HEALTH_AFTER_TURNS {
+		return "by having the most health"
+	}
+	return ""
+}
+
 type GameEvent struct {
 	EventType      GameEventType
 	SourceX        int
@@ -97,473 +258,453 @@ func (g *Game) AcceptMove(move GameMove) []GameEvent {
 	events := make([]GameEvent, 0, 100)
 
 	// we don't actually check if the move is valid (ie at least 1 subaction must be valid)
-	for _, m := range []MapCoord{move.First, move.Second} {
-		if m.X != -1 && m.Y != -1 {
-			g.Map.Tiles[m.X][m.Y].Reversed = !g.Map.Tiles[m.X][m.Y].Reversed
-			events = append(events, GameEvent{
-				EventType: REVERSE_TILE,
-				SourceX:   m.X,
-				SourceY:   m.Y,
-			})
-		}
+	events = g.startTurn(events)
+	events = g.swapReserves(move, g.CurrentTurn, events)
+	events = g.reverseTiles(move, g.CurrentTurn, events)
+	events = g.usePower(move.Power, g.CurrentTurn, events)
+	events = g.advanceArmy(g.CurrentTurn, events)
+	g.CurrentTurn = g.CurrentTurn.Opposite()
+	g.TurnNumber += 1
+	return g.checkWinConditions(events)
+}
+
+// AcceptSimultaneousMoves resolves a round of the simultaneous turn variant. The reversals
+// of both sides are applied first, so a tile chosen by both sides ends up unchanged. Then
+// the army of the side with the initiative (the current turn) advances, followed by the
+// other army, which means collisions are resolved exactly as if the side with the initiative
+// had moved first. The initiative passes to the other side after each round.
+func (g *Game) AcceptSimultaneousMoves(east, west GameMove) []GameEvent {
+	events := make([]GameEvent, 0, 200)
+
+	events = g.startTurn(events)
+	events = g.swapReserves(east, EAST, events)
+	events = g.swapReserves(west, WEST, events)
+	events = g.reverseTiles(east, EAST, events)
+	events = g.reverseTiles(west, WEST, events)
+	events = g.usePower(east.Power, EAST, events)
+	events = g.usePower(west.Power, WEST, events)
+	events = g.advanceArmy(g.CurrentTurn, events)
+	events = g.advanceArmy(g.CurrentTurn.Opposite(), events)
+	g.CurrentTurn = g.CurrentTurn.Opposite()
+	g.TurnNumber += 1
+	return g.checkWinConditions(events)
+}
+
+func (g *Game) startTurn(events []GameEvent) []GameEvent {
+	if g.Rules.SuddenDeath.Turn > 0 && g.TurnNumber == g.Rules.SuddenDeath.Turn {
+		events = append(events, GameEvent{
+			EventType: SUDDEN_DEATH,
+			Value:     g.Rules.SuddenDeath.DamageMultiplier,
+		})
 	}
+	return g.worldEvents(events)
+}
 
-	if g.CurrentTurn == EAST {
-		for i := 0; i < len(g.EastCreatures); i++ {
-			creature := g.EastCreatures[i]
-			if creature.Removed {
-				continue
-			}
-			creatureStartX := creature.X
-			creatureStartY := creature.Y
-			if creature.X < 0 || creature.X >= MAP_WIDTH {
-				creature.X += 1
+func (g *Game) reverseTiles(move GameMove, side Alignment, events []GameEvent) []GameEvent {
+	for _, m := range move.Coords {
+		events = g.reverseTile(g.Map.Tiles[m.X][m.Y], side, events)
+	}
+	return events
+}
 
-				events = append(events, GameEvent{
-					EventType:      MOVE,
-					SourceX:        creatureStartX,
-					SourceY:        creatureStartY,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        creature.Y,
-				})
+// the value of the event is who had reversed the tile before, so that it can be undone.
+// frozen tiles are left as they are
+func (g *Game) reverseTile(tile *Tile, side Alignment, events []GameEvent) []GameEvent {
+	if tile.IsFrozen(g.TurnNumber) {
+		return events
+	}
+	events = append(events, GameEvent{
+		EventType: REVERSE_TILE,
+		SourceX:   tile.X,
+		SourceY:   tile.Y,
+		Value:     int(tile.ReversedBy),
+	})
+	tile.Reversed = !tile.Reversed
+	tile.ReversedBy = side
+	return events
+}
 
-				// if we're still off the map, we don't need to do any other calculations
-				if creature.X < 0 || creature.X >= MAP_WIDTH {
-					continue
-				}
-				// otherwise we need to check enemies, etc
-			} else {
-				tile := g.Map.Tiles[creature.X][creature.Y]
+func (g *Game) Creatures(a Alignment) []*Creature {
+	if a == WEST {
+		return g.WestCreatures
+	}
+	return g.EastCreatures
+}
 
-				if tile.Reversed {
-					creature.Y += 1
-				} else {
-					creature.Y -= 1
-				}
-				creature.X += 1
+func (g *Game) Health(a Alignment) int {
+	return *g.health(a)
+}
 
-				tile.Reversed = !tile.Reversed
-				tile.HasCreature = false
+func (g *Game) health(a Alignment) *int {
+	if a == WEST {
+		return &g.WestHealth
+	}
+	return &g.EastHealth
+}
 
-				events = append(events, GameEvent{
-					EventType:      MOVE,
-					SourceX:        creatureStartX,
-					SourceY:        creatureStartY,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        creature.Y,
-				})
-				events = append(events, GameEvent{
-					EventType: REVERSE_TILE,
-					SourceX:   creatureStartX,
-					SourceY:   creatureStartY,
-				})
-			}
+func (g *Game) kills(a Alignment) *int {
+	if a == WEST {
+		return &g.WestKills
+	}
+	return &g.EastKills
+}
 
-			if creature.Y < creature.X%2 {
-				events = append(events, GameEvent{
-					EventType:      WARP,
-					SourceX:        creature.X,
-					SourceY:        creature.Y,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        2*MAP_HEIGHT - 2 + (creature.X % 2),
-				})
-				creature.Y = 2*MAP_HEIGHT - 2 + (creature.X % 2)
-			}
-			if creature.Y > 2*MAP_HEIGHT-2+(creature.X%2) {
-				events = append(events, GameEvent{
-					EventType:      WARP,
-					SourceX:        creature.X,
-					SourceY:        creature.Y,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        creature.X % 2,
-				})
-				creature.Y = creature.X % 2
-			}
+func IsOffMap(x int) bool {
+	return x < 0 || x >= MAP_WIDTH
+}
 
-			// if we move off the map into opponent territory
-			if creature.X >= MAP_WIDTH {
-				creature.Removed = true
-				g.WestHealth -= creature.Power
+// advanceArmy moves every creature of the side one step towards the opponent. EAST moves
+// right and WEST moves left, and removed creatures are moved far off the map in the
+// direction they were travelling
+func (g *Game) advanceArmy(side Alignment, events []GameEvent) []GameEvent {
+	dx := int(side)
+	creatures := g.Creatures(side)
+	enemies := g.Creatures(side.Opposite())
+
+	for i := 0; i < len(creatures); i++ {
+		creature := creatures[i]
+		if creature.Removed {
+			continue
+		}
+		creatureStartX := creature.X
+		creatureStartY := creature.Y
+		// creatures coming onto the board don't move vertically, but if they land on a
+		// hole they are pushed down to the next tile
+		dy := 1
+		if IsOffMap(creature.X) {
+			creature.X += dx
 
-				events = append(events, GameEvent{
-					EventType:      DEAL_DAMAGE,
-					SourceCreature: creature,
-					TargetX:        int(WEST),
-					Value:          creature.Power,
-				})
-				if g.WestHealth <= 0 {
-					events = append(events, GameEvent{
-						EventType: GAME_OVER,
-						SourceX:   int(EAST),
-						TargetX:   int(WEST),
-					})
-				}
+			events = append(events, GameEvent{
+				EventType:      MOVE,
+				SourceX:        creatureStartX,
+				SourceY:        creatureStartY,
+				SourceCreature: creature,
+				TargetX:        creature.X,
+				TargetY:        creature.Y,
+			})
+			g.countCreature(creature.X, creature.Y, 1)
 
+			// if we're still off the map, we don't need to do any other calculations
+			if IsOffMap(creature.X) {
 				continue
 			}
+			// otherwise we need to check enemies, etc
+		} else {
+			tile := g.Map.Tiles[creature.X][creature.Y]
 
-			for _, c := range g.WestCreatures {
-				if c.Removed {
-					continue
-				}
-				if c.X == creature.X && c.Y == creature.Y {
-					if c.Power == creature.Power {
-						g.Map.Tiles[creature.X][creature.Y].HasCreature = false
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        c.X,
-							SourceY:        c.Y,
-							SourceCreature: c,
-						})
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        creature.X,
-							SourceY:        creature.Y,
-							SourceCreature: creature,
-						})
-						c.X = -1000
-						c.Removed = true
-						creature.X = 1000
-						creature.Removed = true
-
-					} else if c.Power > creature.Power {
-						c.Power -= creature.Power
-						events = append(events, GameEvent{
-							EventType:      UPDATE_POWER,
-							TargetCreature: c,
-							Value:          -creature.Power,
-						})
-
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        creature.X,
-							SourceY:        creature.Y,
-							SourceCreature: creature,
-						})
-						creature.X = 1000
-						creature.Removed = true
-					} else if creature.Power > c.Power {
-						creature.Power -= c.Power
-						events = append(events, GameEvent{
-							EventType:      UPDATE_POWER,
-							TargetCreature: creature,
-							Value:          -c.Power,
-						})
-
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        c.X,
-							SourceY:        c.Y,
-							SourceCreature: c,
-						})
-						c.X = -1000
-						c.Removed = true
-					}
-					break
-				}
+			if !tile.Reversed {
+				dy = -1
 			}
+			creature.Y += dy
+			creature.X += dx
 
-			if !creature.Removed {
-				e := g.Map.Tiles[creature.X][creature.Y].GetActiveEffect()
-				for _, cc := range g.EastCreatures {
-					if cc.ApplyEffect(e) {
-						events = append(events, GameEvent{
-							EventType:      APPLY_EFFECT,
-							TargetCreature: cc,
-							Effect:         e,
-						})
-						events = append(events, GameEvent{
-							EventType:      UPDATE_POWER,
-							TargetCreature: cc,
-							Value:          e.Value,
-						})
-					}
-				}
-				g.Map.Tiles[creature.X][creature.Y].HasCreature = true
-			}
+			events = append(events, GameEvent{
+				EventType:      MOVE,
+				SourceX:        creatureStartX,
+				SourceY:        creatureStartY,
+				SourceCreature: creature,
+				TargetX:        creature.X,
+				TargetY:        creature.Y,
+			})
+			tile.CreatureCount -= 1
+			g.countCreature(creature.X, creature.Y, 1)
+			events = g.reverseTile(tile, side, events)
 		}
-		g.CurrentTurn = WEST
-	} else {
-		for i := 0; i < len(g.WestCreatures); i++ {
-			creature := g.WestCreatures[i]
-			if creature.Removed {
-				continue
-			}
-			creatureStartX := creature.X
-			creatureStartY := creature.Y
-			if creature.X < 0 || creature.X >= MAP_WIDTH {
-				creature.X -= 1
-				events = append(events, GameEvent{
-					EventType:      MOVE,
-					SourceX:        creatureStartX,
-					SourceY:        creatureStartY,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        creature.Y,
-				})
-
-				// if we're still off the map, we don't need to do any other calculations
-				if creature.X < 0 || creature.X >= MAP_WIDTH {
-					continue
-				}
-				// otherwise we need to check enemies, etc
-
-			} else {
-				tile := g.Map.Tiles[creature.X][creature.Y]
-
-				if tile.Reversed {
-					creature.Y += 1
-				} else {
-					creature.Y -= 1
-				}
-				creature.X -= 1
-				tile.Reversed = !tile.Reversed
-				tile.HasCreature = false
-
-				events = append(events, GameEvent{
-					EventType:      MOVE,
-					SourceX:        creatureStartX,
-					SourceY:        creatureStartY,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        creature.Y,
-				})
-				events = append(events, GameEvent{
-					EventType: REVERSE_TILE,
-					SourceX:   creatureStartX,
-					SourceY:   creatureStartY,
-				})
-			}
 
-			if creature.Y < creature.X%2 {
+		if !IsOffMap(creature.X) {
+			if y := g.Map.Shape.WarpY(creature.X, creature.Y, dy); y != creature.Y {
 				events = append(events, GameEvent{
 					EventType:      WARP,
 					SourceX:        creature.X,
 					SourceY:        creature.Y,
 					SourceCreature: creature,
 					TargetX:        creature.X,
-					TargetY:        2*MAP_HEIGHT - 2 + (creature.X % 2),
+					TargetY:        y,
 				})
-				creature.Y = 2*MAP_HEIGHT - 2 + (creature.X % 2)
-			}
-			if creature.Y > 2*MAP_HEIGHT-2+(creature.X%2) {
-				events = append(events, GameEvent{
-					EventType:      WARP,
-					SourceX:        creature.X,
-					SourceY:        creature.Y,
-					SourceCreature: creature,
-					TargetX:        creature.X,
-					TargetY:        creature.X % 2,
-				})
-				creature.Y = creature.X % 2
+				g.countCreature(creature.X, creature.Y, -1)
+				creature.Y = y
+				g.countCreature(creature.X, creature.Y, 1)
 			}
+		}
 
-			// if we move off the map into opponent territory
-			if creature.X < 0 {
-				creature.Removed = true
-				g.EastHealth -= creature.Power
-				events = append(events, GameEvent{
-					EventType:      DEAL_DAMAGE,
-					SourceCreature: creature,
-					TargetX:        int(EAST),
-					Value:          creature.Power,
-				})
-				if g.EastHealth <= 0 {
-					events = append(events, GameEvent{
-						EventType: GAME_OVER,
-						SourceX:   int(WEST),
-						TargetX:   int(EAST),
-					})
-				}
+		// if we move off the map into opponent territory
+		if IsOffMap(creature.X) {
+			target := side.Opposite()
+			creature.Removed = true
+			damage := creature.Power * g.DamageMultiplier(g.TurnNumber)
+			*g.health(target) -= damage
+
+			events = append(events, GameEvent{
+				EventType:      DEAL_DAMAGE,
+				SourceCreature: creature,
+				TargetX:        int(target),
+				Value:          damage,
+			})
+
+			continue
+		}
+
+		for _, c := range enemies {
+			if c.Removed {
 				continue
 			}
-
-			for _, c := range g.EastCreatures {
-				if c.Removed {
-					continue
+			if c.X == creature.X && c.Y == creature.Y {
+				// both creatures lose the power of the other, and the weaker one dies
+				cPower, creaturePower := c.Power, creature.Power
+				if cPower >= creaturePower {
+					events = g.collide(c, creaturePower, events)
+					events = g.collide(creature, cPower, events)
+				} else {
+					events = g.collide(creature, cPower, events)
+					events = g.collide(c, creaturePower, events)
 				}
-				if c.X == creature.X && c.Y == creature.Y {
-					if c.Power == creature.Power {
-						g.Map.Tiles[creature.X][creature.Y].HasCreature = false
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        c.X,
-							SourceY:        c.Y,
-							SourceCreature: c,
-						})
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        creature.X,
-							SourceY:        creature.Y,
-							SourceCreature: creature,
-						})
-
-						c.X = 1000
-						c.Removed = true
-						creature.X = -1000
-						creature.Removed = true
-					} else if c.Power > creature.Power {
-						c.Power -= creature.Power
-						events = append(events, GameEvent{
-							EventType:      UPDATE_POWER,
-							TargetCreature: c,
-							Value:          -creature.Power,
-						})
-
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        creature.X,
-							SourceY:        creature.Y,
-							SourceCreature: creature,
-						})
-						creature.X = -1000
-						creature.Removed = true
-					} else if creature.Power > c.Power {
-						creature.Power -= c.Power
-						events = append(events, GameEvent{
-							EventType:      UPDATE_POWER,
-							TargetCreature: creature,
-							Value:          -c.Power,
-						})
-
-						events = append(events, GameEvent{
-							EventType:      DEATH,
-							SourceX:        c.X,
-							SourceY:        c.Y,
-							SourceCreature: c,
-						})
-						c.X = 1000
-						c.Removed = true
-					}
-					break
+				// the survivor only levels up after the collision is over
+				if g.Rules.Leveling && c.Removed && !creature.Removed {
+					events = g.gainExperience(creature, events)
+				} else if g.Rules.Leveling && creature.Removed && !c.Removed {
+					events = g.gainExperience(c, events)
 				}
+				break
 			}
+		}
 
-			if !creature.Removed {
-				e := g.Map.Tiles[creature.X][creature.Y].GetActiveEffect()
-				for _, cc := range g.WestCreatures {
-					if cc.ApplyEffect(e) {
-						events = append(events, GameEvent{
-							EventType:      APPLY_EFFECT,
-							TargetCreature: cc,
-							Effect:         e,
-						})
-						events = append(events, GameEvent{
-							EventType:      UPDATE_POWER,
-							TargetCreature: cc,
-							Value:          e.Value,
-						})
-					}
+		if !creature.Removed {
+			e := g.Map.Tiles[creature.X][creature.Y].GetActiveEffect()
+			// a shield is a shield, there's nothing to add to it
+			if g.IsSuddenDeath(g.TurnNumber) && e.Kind != SHIELD_EFFECT {
+				e.Value += g.Rules.SuddenDeath.EffectBonus
+			}
+			for _, cc := range creatures {
+				if cc.ApplyEffect(e) {
+					events = append(events, GameEvent{
+						EventType:      APPLY_EFFECT,
+						TargetCreature: cc,
+						Effect:         e,
+					})
+					events = append(events, effectEvent(cc, e))
 				}
-				g.Map.Tiles[creature.X][creature.Y].HasCreature = true
 			}
 		}
-		g.CurrentTurn = EAST
+	}
+
+	// allies only merge once the whole army has moved, so that a creature doesn't absorb
+	// one that was about to step off its tile
+	if g.Rules.MergeAllies {
+		for _, c := range creatures {
+			if !c.Removed && !IsOffMap(c.X) {
+				events = g.mergeAllies(c, creatures, events)
+			}
+		}
 	}
 	return events
 }
 
-func (g *Game) IsMoveLocationsEmpty(m GameMove) bool {
-	return ((m.First.X == -1 && m.First.Y == -1) || (!g.Map.Tiles[m.First.X][m.First.Y].HasCreature)) &&
-		((m.Second.X == -1 && m.Second.Y == -1) || !g.Map.Tiles[m.Second.X][m.Second.Y].HasCreature)
+// effectEvent is the event for what an applied effect gave the creature
+func effectEvent(c *Creature, e Effect) GameEvent {
+	if e.Kind == ARMOR_EFFECT {
+		return GameEvent{
+			EventType:      UPDATE_ARMOR,
+			TargetCreature: c,
+			Value:          e.Value,
+		}
+	} else if e.Kind == SHIELD_EFFECT {
+		return GameEvent{
+			EventType:      SHIELD_CREATURE,
+			TargetCreature: c,
+		}
+	}
+	return GameEvent{
+		EventType:      UPDATE_POWER,
+		TargetCreature: c,
+		Value:          e.Value,
+	}
 }
 
-func (g *Game) GenerateFirstSteps() []GameMove {
-	moves := make([]GameMove, 0)
-	for i := 0; i < len(g.AllCoords); i++ {
-		ci := g.AllCoords[i]
-		if g.Map.Tiles[ci.X][ci.Y].HasCreature {
+// mergeAllies makes the creature absorb the power of any allies on its tile. The absorbed
+// creatures are removed like dead ones, but they don't count as kills
+func (g *Game) mergeAllies(creature *Creature, allies []*Creature, events []GameEvent) []GameEvent {
+	for _, a := range allies {
+		if a == creature || a.Removed || a.X != creature.X || a.Y != creature.Y {
 			continue
 		}
-		moves = append(moves, GameMove{
-			First:  ci,
-			Second: MapCoord{-1, -1},
+		events = append(events, GameEvent{
+			EventType:      MERGE,
+			SourceX:        a.X,
+			SourceY:        a.Y,
+			SourceCreature: a,
+			TargetCreature: creature,
+			Value:          a.Power,
 		})
+		creature.Power += a.Power
+		g.countCreature(a.X, a.Y, -1)
+		a.X = 1000 * int(a.Alignment)
+		a.Removed = true
 	}
+	return events
+}
 
-	return moves
+// countCreature keeps track of the number of creatures on a tile, if there is one at the
+// coordinate. Every event that moves a creature on or off a tile counts it, so that undoing
+// the events gets the counts back
+func (g *Game) countCreature(x, y, delta int) {
+	if t := g.Map.Tile(x, y); t != nil {
+		t.CreatureCount += delta
+	}
 }
 
-func (g *Game) GenerateLegalMoves() []GameMove {
-	moves := make([]GameMove, 0, len(g.AllCoords))
-	for i := 0; i < len(g.AllCoords); i++ {
-		ci := g.AllCoords[i]
-		if g.Map.Tiles[ci.X][ci.Y].HasCreature {
-			continue
+// collide makes the creature lose power in a collision, killing it if that is all of its
+// power. A shield takes the hit instead. Dead creatures are moved far off the map in the
+// direction they were travelling
+func (g *Game) collide(c *Creature, loss int, events []GameEvent) []GameEvent {
+	if c.Shielded {
+		c.Shielded = false
+		return append(events, GameEvent{
+			EventType:      BREAK_SHIELD,
+			TargetCreature: c,
+		})
+	}
+	if c.Armor > 0 && loss > 0 {
+		absorbed := loss
+		if absorbed > c.Armor {
+			absorbed = c.Armor
 		}
-		for j := i + 1; j < len(g.AllCoords); j++ {
-			cj := g.AllCoords[j]
-			if g.Map.Tiles[cj.X][cj.Y].HasCreature {
-				continue
-			}
-			moves = append(moves, GameMove{
-				First:  ci,
-				Second: cj,
-			})
+		c.Armor -= absorbed
+		loss -= absorbed
+		events = append(events, GameEvent{
+			EventType:      UPDATE_ARMOR,
+			TargetCreature: c,
+			Value:          -absorbed,
+		})
+		if loss == 0 {
+			return events
 		}
-		moves = append(moves, GameMove{
-			First:  ci,
-			Second: MapCoord{-1, -1},
+	}
+	if loss < c.Power {
+		c.Power -= loss
+		return append(events, GameEvent{
+			EventType:      UPDATE_POWER,
+			TargetCreature: c,
+			Value:          -loss,
 		})
 	}
-	return moves
+
+	events = append(events, GameEvent{
+		EventType:      DEATH,
+		SourceX:        c.X,
+		SourceY:        c.Y,
+		SourceCreature: c,
+	})
+	g.countCreature(c.X, c.Y, -1)
+	c.X = 1000 * int(c.Alignment)
+	c.Removed = true
+	*g.kills(c.Alignment.Opposite()) += 1
+	return events
 }
 
-func (g *Game) GenerateLegalMovesWithExclusions(m GameMove) []GameMove {
-	moves := make([]GameMove, 0, len(g.AllCoords))
-	for i := 0; i < len(g.AllCoords); i++ {
-		ci := g.AllCoords[i]
-		if g.Map.Tiles[ci.X][ci.Y].HasCreature {
-			continue
-		}
-		if (ci.X == m.First.X && ci.Y == m.First.Y) || (ci.X == m.Second.X && ci.Y == m.Second.Y) {
-			continue
+func (g *Game) ReversalsAllowed(a Alignment) int {
+	return g.Rules.Reversals() + g.Rules.Handicap(a).ExtraReversals
+}
+
+// turnsBetweenMoves is how many turns go by from one move of a side to its next
+func (g *Game) turnsBetweenMoves() int {
+	if g.Rules.Simultaneous {
+		return 1
+	}
+	return 2
+}
+
+// IsSuddenDeath tells whether sudden death applies to the move made at the given turn
+func (g *Game) IsSuddenDeath(turn int) bool {
+	return g.Rules.SuddenDeath.Turn > 0 && turn >= g.Rules.SuddenDeath.Turn
+}
+
+func (g *Game) DamageMultiplier(turn int) int {
+	if g.IsSuddenDeath(turn) && g.Rules.SuddenDeath.DamageMultiplier > 1 {
+		return g.Rules.SuddenDeath.DamageMultiplier
+	}
+	return 1
+}
+
+func (g *Game) AllUncheckedMovesFor(a Alignment) []GameMove {
+	if a == WEST {
+		return g.WestUncheckedMoves
+	}
+	return g.EastUncheckedMoves
+}
+
+// LoseOnTime ends the game in favour of the opponent of the given side.
+func (g *Game) LoseOnTime(a Alignment) []GameEvent {
+	g.Winner = a.Opposite()
+	g.Result = OUT_OF_TIME
+	return []GameEvent{{
+		EventType: GAME_OVER,
+		SourceX:   int(a.Opposite()),
+		TargetX:   int(a),
+		Value:     int(OUT_OF_TIME),
+	}}
+}
+
+// IsMoveLocationsEmpty tells whether every tile of the move can be reversed, which means
+// it has no creature on it and isn't frozen
+func (g *Game) IsMoveLocationsEmpty(m GameMove) bool {
+	for _, c := range m.Coords {
+		t := g.Map.Tiles[c.X][c.Y]
+		if t.HasCreature() || t.IsFrozen(g.TurnNumber) {
+			return false
 		}
+	}
+	return true
+}
 
-		for j := i + 1; j < len(g.AllCoords); j++ {
-			cj := g.AllCoords[j]
-			if g.Map.Tiles[cj.X][cj.Y].HasCreature {
-				continue
-			}
-			if (cj.X == m.First.X && cj.Y == m.First.Y) || (cj.X == m.Second.X && cj.Y == m.Second.Y) {
-				continue
-			}
-			moves = append(moves, GameMove{
-				First:  ci,
-				Second: cj,
-			})
+func (g *Game) EmptyCoords() []MapCoord {
+	coords := make([]MapCoord, 0, len(g.AllCoords))
+	for _, c := range g.AllCoords {
+		t := g.Map.Tiles[c.X][c.Y]
+		if !t.HasCreature() && !t.IsFrozen(g.TurnNumber) {
+			coords = append(coords, c)
 		}
-		moves = append(moves, GameMove{
-			First:  ci,
-			Second: MapCoord{-1, -1},
-		})
+	}
+	return coords
+}
+
+func (g *Game) GenerateFirstSteps() []GameMove {
+	moves := make([]GameMove, 0)
+	for _, c := range g.EmptyCoords() {
+		moves = append(moves, NewGameMove(c))
 	}
 	return moves
 }
 
-func (g *Game) GenerateNextSteps(m MapCoord) []GameMove {
-	if m.X == -1 && m.Y == -1 {
+func (g *Game) GenerateLegalMoves() []GameMove {
+	return AppendCombinations(nil, g.EmptyCoords(), GameMove{}, g.ReversalsAllowed(g.CurrentTurn))
+}
+
+func (g *Game) GenerateLegalMovesWithExclusions(m GameMove) []GameMove {
+	coords := make([]MapCoord, 0, len(g.AllCoords))
+	for _, c := range g.EmptyCoords() {
+		if !m.Contains(c) {
+			coords = append(coords, c)
+		}
+	}
+	return AppendCombinations(nil, coords, GameMove{}, g.ReversalsAllowed(g.CurrentTurn))
+}
+
+// GenerateNextSteps returns the partial move extended by each possible next reversal,
+// along with the partial move itself
+func (g *Game) GenerateNextSteps(m GameMove) []GameMove {
+	if len(m.Coords) == 0 {
 		return []GameMove{}
 	}
 
 	moves := make([]GameMove, 0, len(g.AllCoords)+1)
-	for i := 0; i < len(g.AllCoords); i++ {
-		ci := g.AllCoords[i]
-		if g.Map.Tiles[ci.X][ci.Y].HasCreature || (ci.X == m.X && ci.Y == m.Y) {
-			continue
+	if len(m.Coords) < g.ReversalsAllowed(g.CurrentTurn) {
+		for _, c := range g.EmptyCoords() {
+			if m.Contains(c) {
+				continue
+			}
+			moves = append(moves, m.With(c))
 		}
-		moves = append(moves, GameMove{
-			First:  m,
-			Second: ci,
-		})
 	}
-	moves = append(moves, GameMove{
-		First:  m,
-		Second: MapCoord{-1, -1},
-	})
+	moves = append(moves, m)
 	return moves
 }
@@ -0,0 +1,222 @@
+package core_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// gamesEqual compares every field of the games, following pointers, and reports the first
+// one that differs
+func gamesEqual(g1, g2 *core.Game) error {
+	v1, v2 := reflect.ValueOf(g1).Elem(), reflect.ValueOf(g2).Elem()
+	for i := 0; i < v1.NumField(); i++ {
+		if !reflect.DeepEqual(v1.Field(i).Interface(), v2.Field(i).Interface()) {
+			return fmt.Errorf("games have different values for %s", v1.Type().Field(i).Name)
+		}
+	}
+	return nil
+}
+
+// placeCreature moves the creature straight to a tile, for setting up positions
+func placeCreature(g *core.Game, c *core.Creature, x, y int) {
+	c.X, c.Y = x, y
+	g.Map.Tiles[x][y].CreatureCount += 1
+}
+
+// acceptAndUndo plays the move and checks that undoing it gets back to the position set
+// up by setup, returning the events of the move
+func acceptAndUndo(t *testing.T, setup func() *core.Game, m core.GameMove) []core.GameEvent {
+	t.Helper()
+	g := setup()
+	events := g.AcceptMove(m)
+	g.UndoEvents(events)
+	if err := gamesEqual(g, setup()); err != nil {
+		t.Fatalf("undoing the move: %s", err)
+	}
+	return events
+}
+
+func TestSuddenDeath(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.SuddenDeath = core.SuddenDeath{Turn: 4, DamageMultiplier: 3, EffectBonus: 2}
+	game := core.NewGameWithRules(5, rules)
+	if game.IsSuddenDeath(3) || !game.IsSuddenDeath(4) || game.DamageMultiplier(3) != 1 || game.DamageMultiplier(4) != 3 {
+		t.Fatal("sudden death should start at turn 4")
+	}
+
+	// the event comes with the first move of sudden death and no other
+	random := rand.New(rand.NewSource(5))
+	played := make([][]core.GameEvent, 0)
+	for game.TurnNumber < 8 && !game.IsOver() {
+		turn := game.TurnNumber
+		moves := game.GenerateLegalMoves()
+		events := game.AcceptMove(moves[random.Intn(len(moves))])
+		announced := false
+		for _, e := range events {
+			if e.EventType == core.SUDDEN_DEATH {
+				announced = e.Value == 3
+			}
+		}
+		if announced != (turn == 4) {
+			t.Fatalf("sudden death announced at turn %d: %v", turn, announced)
+		}
+		played = append(played, events)
+	}
+	for i := len(played) - 1; i >= 0; i-- {
+		game.UndoEvents(played[i])
+	}
+	if err := gamesEqual(game, core.NewGameWithRules(5, rules)); err != nil {
+		t.Fatal(err)
+	}
+
+	// a creature reaching the base deals multiplied damage
+	setup := func() *core.Game {
+		g := core.NewGameWithRules(9, rules)
+		g.TurnNumber = 4
+		placeCreature(g, g.EastCreatures[1], core.MAP_WIDTH-1, 2)
+		return g
+	}
+	dealt := 0
+	for _, e := range acceptAndUndo(t, setup, core.NewGameMove()) {
+		if e.EventType == core.DEAL_DAMAGE && e.SourceCreature.Id == setup().EastCreatures[1].Id {
+			dealt = e.Value
+		}
+	}
+	if power := setup().EastCreatures[1].Power; dealt != 3*power {
+		t.Fatalf("dealt %d damage, expected triple the power of %d", dealt, power)
+	}
+
+	// power and armor effects get the bonus, a shield stays a shield
+	for _, kind := range []core.EffectKind{core.POWER_EFFECT, core.ARMOR_EFFECT, core.SHIELD_EFFECT} {
+		setup = func() *core.Game {
+			g := core.NewGameWithRules(9, rules)
+			g.TurnNumber = 4
+			placeCreature(g, g.EastCreatures[1], 1, 3)
+			g.Map.Tiles[1][3].Reversed = false
+			e := core.Effect{Kind: kind, X: 2, Y: 2, Targets: core.TILE, Value: 1}
+			g.Map.Tiles[2][2].ObverseEffect = e
+			g.Map.Tiles[2][2].ReverseEffect = e
+			return g
+		}
+		expected := 3
+		if kind == core.SHIELD_EFFECT {
+			expected = 1
+		}
+		applied := 0
+		for _, e := range acceptAndUndo(t, setup, core.NewGameMove()) {
+			if e.EventType == core.APPLY_EFFECT && e.Effect.X == 2 && e.Effect.Y == 2 {
+				applied = e.Effect.Value
+			}
+		}
+		if applied != expected {
+			t.Fatalf("effect kind %d applied with value %d, expected %d", kind, applied, expected)
+		}
+	}
+}
+
+func TestMergeAllies(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.MergeAllies = true
+	setup := func() *core.Game {
+		g := core.NewGameWithRules(8, rules)
+		// two allies that both step onto (2, 2), one going down and one going up
+		placeCreature(g, g.EastCreatures[1], 1, 3)
+		placeCreature(g, g.EastCreatures[2], 1, 1)
+		g.Map.Tiles[1][3].Reversed = false
+		g.Map.Tiles[1][1].Reversed = true
+		return g
+	}
+	game := setup()
+	a, b := game.EastCreatures[1], game.EastCreatures[2]
+
+	events := game.AcceptMove(core.NewGameMove())
+	merged := false
+	for _, e := range events {
+		if e.EventType == core.MERGE && e.SourceCreature == b && e.TargetCreature == a {
+			merged = true
+		}
+	}
+	if !merged || !b.Removed || a.Removed || a.X != 2 || a.Y != 2 {
+		t.Fatalf("creatures didn't merge: %s %s", a, b)
+	}
+	if game.Map.Tiles[2][2].CreatureCount != 1 {
+		t.Fatalf("tile has %d creatures after merging", game.Map.Tiles[2][2].CreatureCount)
+	}
+	game.UndoEvents(events)
+	if err := gamesEqual(game, setup()); err != nil {
+		t.Fatal(err)
+	}
+
+	// an ally that steps off the tile before the rest of the army is done moving isn't
+	// absorbed by the creature that steps onto it
+	game = core.NewGameWithRules(8, rules)
+	c, ally := game.EastCreatures[0], game.EastCreatures[1]
+	placeCreature(game, ally, 0, c.Y)
+	for _, e := range game.AcceptMove(core.NewGameMove()) {
+		if e.EventType == core.MERGE {
+			t.Fatalf("%s merged into %s", e.SourceCreature, e.TargetCreature)
+		}
+	}
+	if c.Removed || ally.Removed || ally.X != 1 {
+		t.Fatalf("both creatures should still be moving: %s %s", c, ally)
+	}
+}
+
+func TestLeveling(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.Leveling = true
+	game := core.NewGameWithRules(9, rules)
+	random := rand.New(rand.NewSource(9))
+
+	for i := 0; i < 80 && !game.IsOver(); i++ {
+		moves := game.GenerateLegalMoves()
+		for _, e := range game.AcceptMove(moves[random.Intn(len(moves))]) {
+			if e.EventType == core.LEVEL_UP {
+				return
+			}
+		}
+	}
+	t.Fatal("no creature levelled up")
+}
+
+func TestArmor(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.Armor = true
+	rules.MapSymmetry = core.MIRROR_SYMMETRY
+	game := core.NewGameWithRules(4, rules)
+
+	armored := 0
+	for _, c := range game.AllCoords {
+		tile := game.Map.Tiles[c.X][c.Y]
+		mx, my := core.MirrorCoord(c.X, c.Y, rules.MapSymmetry)
+		mirror := game.Map.Tiles[mx][my]
+		if tile.ObverseEffect.Kind != mirror.ObverseEffect.Kind || tile.ObverseEffect.Value != mirror.ObverseEffect.Value {
+			t.Fatalf("armor effects at %d %d aren't symmetric", c.X, c.Y)
+		}
+		if tile.ObverseEffect.Kind != core.POWER_EFFECT {
+			armored++
+		}
+		if tile.ReverseEffect.Kind != core.POWER_EFFECT {
+			armored++
+		}
+	}
+	if armored == 0 {
+		t.Fatal("no armor effects on the map")
+	}
+
+	// armor takes the damage before power does
+	c := game.EastCreatures[0]
+	c.Armor = 3
+	c.Power = 5
+	enemy := game.WestCreatures[0]
+	enemy.Power = 4
+	placeCreature(game, enemy, 0, c.Y)
+	game.AcceptMove(core.NewGameMove())
+	if c.Removed || c.Armor != 0 || c.Power != 4 || !enemy.Removed {
+		t.Fatalf("armor didn't absorb the collision: armor %d %s %s", c.Armor, c, enemy)
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+// Hash sums up the parts of the game that change during play, so that searches can
+// recognise a position reached by different moves. Games with different seeds or rules
+// can share hashes. Tile effects are left out since they only depend on the turn number.
+func (g *Game) Hash() uint64 {
+	h := mix(uint64(g.TurnNumber))
+	h = mix(h ^ pack(int(g.CurrentTurn), g.EastHealth, g.WestHealth, int(g.Winner)))
+	h = mix(h ^ pack(g.EastKills, g.WestKills, int(g.Result), 0))
+	for p := 0; p < int(NUM_POWERS); p++ {
+		h = mix(h ^ pack(g.EastPowersReady[p], g.WestPowersReady[p], p, 0))
+	}
+	for _, c := range g.AllCoords {
+		t := g.Map.Tiles[c.X][c.Y]
+		reversed := 0
+		if t.Reversed {
+			reversed = 1
+		}
+		h = mix(h ^ pack(reversed, int(t.ReversedBy), t.FrozenUntil, c.X*2*MAP_HEIGHT+c.Y))
+	}
+	for _, c := range g.EastCreatures {
+		h = c.hash(h)
+	}
+	for _, c := range g.WestCreatures {
+		h = c.hash(h)
+	}
+	return h
+}
+
+// pack puts four small numbers into 16 bits each
+func pack(a, b, c, d int) uint64 {
+	return uint64(uint16(a)) | uint64(uint16(b))<<16 | uint64(uint16(c))<<32 | uint64(uint16(d))<<48
+}
+
+// hash mixes the creature into h. armor and experience get their own word, they can
+// grow past what's left of the first one
+func (c *Creature) hash(h uint64) uint64 {
+	flags := 0
+	if c.Removed {
+		flags |= 1
+	}
+	if c.Shielded {
+		flags |= 2
+	}
+	h = mix(h ^ pack(c.X, c.Y, c.Power, flags))
+	return mix(h ^ pack(c.Armor, c.Experience, 0, 0))
+}
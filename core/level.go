@@ -0,0 +1,42 @@
+package core
+
+// the experience needed for each level
+var LEVEL_THRESHOLDS = []int{1, 3, 6}
+
+// power gained on reaching each level
+const LEVEL_POWER_BONUS = 2
+
+func (c *Creature) Level() int {
+	level := 0
+	for _, t := range LEVEL_THRESHOLDS {
+		if c.Experience >= t {
+			level += 1
+		}
+	}
+	return level
+}
+
+// gainExperience rewards the creature for a kill. Levelling up makes it permanently stronger
+func (g *Game) gainExperience(c *Creature, events []GameEvent) []GameEvent {
+	level := c.Level()
+	c.Experience += 1
+	events = append(events, GameEvent{
+		EventType:      GAIN_EXPERIENCE,
+		TargetCreature: c,
+	})
+	if c.Level() == level {
+		return events
+	}
+
+	c.Power += LEVEL_POWER_BONUS
+	events = append(events, GameEvent{
+		EventType:      LEVEL_UP,
+		TargetCreature: c,
+		Value:          c.Level(),
+	})
+	return append(events, GameEvent{
+		EventType:      UPDATE_POWER,
+		TargetCreature: c,
+		Value:          LEVEL_POWER_BONUS,
+	})
+}
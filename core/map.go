@@ -7,7 +7,17 @@ type MapCoord struct {
 }
 
 type Map struct {
+	// tiles that aren't part of the shape of the board are nil
 	Tiles [][]*Tile
+	Shape MapShape
+}
+
+// Tile returns the tile at the coordinate, or nil if the board has no tile there
+func (m *Map) Tile(x, y int) *Tile {
+	if !m.Shape.HasTile(x, y) {
+		return nil
+	}
+	return m.Tiles[x][y]
 }
 
 type Tile struct {
@@ -16,7 +26,16 @@ type Tile struct {
 	ObverseEffect Effect
 	ReverseEffect Effect
 	Reversed      bool
-	HasCreature   bool
+	// the side that last reversed the tile, or 0 if it has never been reversed
+	ReversedBy Alignment
+	// the number of creatures on the tile. allies can share a tile, so this isn't a bool
+	CreatureCount int
+	// the tile can't be reversed before this turn
+	FrozenUntil int
+}
+
+func (t *Tile) HasCreature() bool {
+	return t.CreatureCount > 0
 }
 
 func (t *Tile) GetActiveEffect() Effect {
@@ -34,7 +53,6 @@ func RandomTile(x, y int, random *rand.Rand) *Tile {
 		Reversed:      false,
 		ObverseEffect: RandomEffect(x, y, random),
 		ReverseEffect: RandomEffect(x, y, random),
-		HasCreature:   false,
 	}
 }
 
@@ -42,15 +60,126 @@ const MAP_HEIGHT = 3
 const MAP_WIDTH = 5
 
 func NewMap(random *rand.Rand) *Map {
+	return NewShapedMap(random, FULL_SHAPE)
+}
+
+func NewShapedMap(random *rand.Rand, shape MapShape) *Map {
+	tiles := make([][]*Tile, MAP_WIDTH)
+	for i := 0; i < MAP_WIDTH; i++ {
+		j := i % 2
+		tiles[i] = make([]*Tile, 2*MAP_HEIGHT-1+j)
+		for ; j < 2*MAP_HEIGHT; j += 2 {
+			if shape.HasTile(i, j) {
+				tiles[i][j] = RandomTile(i, j, random)
+			}
+		}
+	}
+	return &Map{
+		Tiles: tiles,
+		Shape: shape,
+	}
+}
+
+// Coords lists the coordinates of every tile on the board
+func (m *Map) Coords() []MapCoord {
+	coords := make([]MapCoord, 0, MAP_HEIGHT*MAP_WIDTH)
+	for i := 0; i < MAP_WIDTH; i++ {
+		for j := i % 2; j < 2*MAP_HEIGHT; j += 2 {
+			if m.Shape.HasTile(i, j) {
+				coords = append(coords, MapCoord{i, j})
+			}
+		}
+	}
+	return coords
+}
+
+type MapSymmetry int
+
+const (
+	NO_SYMMETRY MapSymmetry = iota
+	// the east half of the map is the west half reflected across the middle column
+	MIRROR_SYMMETRY
+	// the east half of the map is the west half rotated around the center of the map.
+	// only the tiles and starting creatures are rotated, creatures still move up and down the
+	// same way for both sides, so unlike a mirrored map this doesn't make the game fair
+	POINT_SYMMETRY
+)
+
+// MirrorCoord returns the coordinate that plays the same role for the other side.
+// Like the creature layout, this assumes the map has an odd width so that mirrored
+// columns have the same parity.
+func MirrorCoord(x, y int, symmetry MapSymmetry) (int, int) {
+	mx := MAP_WIDTH - 1 - x
+	if symmetry == POINT_SYMMETRY {
+		return mx, 2*(MAP_HEIGHT-1) + 2*(x%2) - y
+	}
+	return mx, y
+}
+
+// NewSymmetricMap rolls the tiles of the west half of the map and copies them to the
+// matching tiles of the east half, see MapSymmetry for how even that makes the game
+func NewSymmetricMap(random *rand.Rand, symmetry MapSymmetry, shape MapShape) *Map {
+	if symmetry == NO_SYMMETRY {
+		return NewShapedMap(random, shape)
+	}
+
 	tiles := make([][]*Tile, MAP_WIDTH)
 	for i := 0; i < MAP_WIDTH; i++ {
 		j := i % 2
 		tiles[i] = make([]*Tile, 2*MAP_HEIGHT-1+j)
 		for ; j < 2*MAP_HEIGHT; j += 2 {
-			tiles[i][j] = RandomTile(i, j, random)
+			if !shape.HasTile(i, j) {
+				continue
+			}
+			if isRolled(i, j, symmetry, shape) {
+				tiles[i][j] = RandomTile(i, j, random)
+				continue
+			}
+
+			mx, my := MirrorCoord(i, j, symmetry)
+			mirror := tiles[mx][my]
+			tile := &Tile{
+				X:             i,
+				Y:             j,
+				ObverseEffect: mirror.ObverseEffect,
+				ReverseEffect: mirror.ReverseEffect,
+			}
+			tile.ObverseEffect.X, tile.ObverseEffect.Y = i, j
+			tile.ReverseEffect.X, tile.ReverseEffect.Y = i, j
+			tiles[i][j] = tile
 		}
 	}
 	return &Map{
 		Tiles: tiles,
+		Shape: shape,
+	}
+}
+
+// isRolled tells whether a tile of a symmetric map is rolled rather than copied from the
+// mirrored tile
+func isRolled(x, y int, symmetry MapSymmetry, shape MapShape) bool {
+	if symmetry == NO_SYMMETRY {
+		return true
+	}
+	mx, my := MirrorCoord(x, y, symmetry)
+	// a shape that isn't symmetric may be missing the mirrored tile
+	return mx > x || (mx == x && my >= y) || !shape.HasTile(mx, my)
+}
+
+// AddArmorEffects turns some of the effects into armor and shield effects, keeping the
+// map symmetric
+func (m *Map) AddArmorEffects(random *rand.Rand, symmetry MapSymmetry) {
+	for _, c := range m.Coords() {
+		tile := m.Tiles[c.X][c.Y]
+		if isRolled(c.X, c.Y, symmetry, m.Shape) {
+			tile.ObverseEffect = ArmorEffect(tile.ObverseEffect, random)
+			tile.ReverseEffect = ArmorEffect(tile.ReverseEffect, random)
+			continue
+		}
+
+		mx, my := MirrorCoord(c.X, c.Y, symmetry)
+		mirror := m.Tiles[mx][my]
+		tile.ObverseEffect.Kind, tile.ObverseEffect.Value = mirror.ObverseEffect.Kind, mirror.ObverseEffect.Value
+		tile.ReverseEffect.Kind, tile.ReverseEffect.Value = mirror.ReverseEffect.Kind, mirror.ReverseEffect.Value
 	}
 }
@@ -0,0 +1,105 @@
+package core_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+func TestSymmetricMaps(t *testing.T) {
+	type coord struct{ x, y int }
+	cases := []struct {
+		symmetry core.MapSymmetry
+		// tiles and their mirrors on the 5x3 map
+		mirrors map[coord]coord
+		// where the first west creatures start, east ones start at (-1,2), (-3,4), (-5,0)
+		west []coord
+	}{
+		{
+			symmetry: core.MIRROR_SYMMETRY,
+			mirrors: map[coord]coord{
+				{0, 0}: {4, 0},
+				{0, 4}: {4, 4},
+				{1, 1}: {3, 1},
+				{1, 5}: {3, 5},
+				{2, 2}: {2, 2},
+			},
+			west: []coord{{5, 2}, {7, 4}, {9, 0}},
+		},
+		{
+			symmetry: core.POINT_SYMMETRY,
+			mirrors: map[coord]coord{
+				{0, 0}: {4, 4},
+				{0, 4}: {4, 0},
+				{1, 1}: {3, 5},
+				{1, 5}: {3, 1},
+				{2, 0}: {2, 4},
+				{2, 2}: {2, 2},
+			},
+			west: []coord{{5, 2}, {7, 0}, {9, 4}},
+		},
+	}
+
+	for _, c := range cases {
+		for from, to := range c.mirrors {
+			if x, y := core.MirrorCoord(from.x, from.y, c.symmetry); x != to.x || y != to.y {
+				t.Errorf("symmetry %d: %v mirrors to (%d,%d), want %v", c.symmetry, from, x, y, to)
+			}
+		}
+
+		for seed := int64(0); seed < 20; seed++ {
+			m := core.NewSymmetricMap(rand.New(rand.NewSource(seed)), c.symmetry, core.FULL_SHAPE)
+			for from, to := range c.mirrors {
+				t1, t2 := m.Tiles[from.x][from.y], m.Tiles[to.x][to.y]
+				if t1.ObverseEffect.Value != t2.ObverseEffect.Value || t1.ObverseEffect.Targets != t2.ObverseEffect.Targets ||
+					t1.ReverseEffect.Value != t2.ReverseEffect.Value || t1.ReverseEffect.Targets != t2.ReverseEffect.Targets {
+					t.Fatalf("symmetry %d seed %d: tiles %v and %v have different effects", c.symmetry, seed, from, to)
+				}
+				if t2.ObverseEffect.X != to.x || t2.ObverseEffect.Y != to.y {
+					t.Fatalf("symmetry %d seed %d: effect of tile %v is at (%d,%d)", c.symmetry, seed, to, t2.ObverseEffect.X, t2.ObverseEffect.Y)
+				}
+			}
+		}
+
+		rules := core.DefaultRules()
+		rules.MapSymmetry = c.symmetry
+		g := core.NewGameWithRules(1, rules)
+		for i, want := range c.west {
+			ec, wc := g.EastCreatures[i], g.WestCreatures[i]
+			if wc.X != want.x || wc.Y != want.y {
+				t.Errorf("symmetry %d: west creature %d starts at (%d,%d), want %v", c.symmetry, i, wc.X, wc.Y, want)
+			}
+			if wc.Power != ec.Power || wc.Species != ec.Species || wc.Alignment != core.WEST {
+				t.Errorf("symmetry %d: west creature %d doesn't match the east one", c.symmetry, i)
+			}
+		}
+	}
+}
+
+func TestMapShapes(t *testing.T) {
+	for _, shape := range []core.MapShape{core.HOLES_SHAPE, core.NARROW_SHAPE, core.DIAMOND_SHAPE} {
+		rules := core.DefaultRules()
+		rules.MapShape = shape
+		rules.MapSymmetry = core.POINT_SYMMETRY
+		game := core.NewGameWithRules(3, rules)
+		random := rand.New(rand.NewSource(3))
+
+		for i := 0; i < 60 && !game.IsOver(); i++ {
+			moves := game.GenerateLegalMoves()
+			m := moves[random.Intn(len(moves))]
+			for _, c := range m.Coords {
+				if game.Map.Tile(c.X, c.Y) == nil {
+					t.Fatalf("%s board: move reverses the hole at %d %d", shape.Name, c.X, c.Y)
+				}
+			}
+			game.AcceptMove(m)
+
+			for _, c := range append(game.EastCreatures, game.WestCreatures...) {
+				if !c.Removed && !core.IsOffMap(c.X) && game.Map.Tile(c.X, c.Y) == nil {
+					t.Fatalf("%s board: creature is in the hole at %d %d", shape.Name, c.X, c.Y)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package core
+
+import "context"
+
+// Player chooses the moves of one side of a game. It could be a person clicking on the
+// board, a search like the negamax agent, or anything else that can pick a move.
+type Player interface {
+	// ChooseMove picks a move for the side of the view. Players should give up once the
+	// context is done, either returning an error or the best move they have so far
+	ChooseMove(ctx context.Context, view GameView) (GameMove, error)
+}
+
+// GameView is a read-only look at a game for a Player. The game is copied when the view
+// is made, so the player can think in the background while the real game goes on
+type GameView struct {
+	game *Game
+	// the side that the move is chosen for. in the simultaneous variant this isn't
+	// always the current turn
+	Side Alignment
+}
+
+func NewGameView(g *Game, side Alignment) GameView {
+	return GameView{game: g.Clone(), Side: side}
+}
+
+// Game returns a copy of the game that the player is free to change, for example to
+// search it
+func (v GameView) Game() *Game {
+	return v.game.Clone()
+}
+
+func (v GameView) TurnNumber() int {
+	return v.game.TurnNumber
+}
+
+// LegalMoves lists the reversals the side of the view may choose from
+func (v GameView) LegalMoves() []GameMove {
+	g := v.Game()
+	g.CurrentTurn = v.Side
+	return g.GenerateLegalMoves()
+}
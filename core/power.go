@@ -0,0 +1,188 @@
+package core
+
+// Power is an ability a player can use once in a while on top of their reversals
+type Power int
+
+const (
+	NO_POWER Power = iota
+	// the tile can't be reversed, by either side or by creatures leaving it, until the
+	// player's next turn
+	FREEZE
+	// the creature loses nothing in its next collision
+	SHIELD
+	// the player's base gets back some health
+	HEAL
+	NUM_POWERS
+)
+
+const HEAL_AMOUNT = 5
+
+var ALL_POWERS = []Power{FREEZE, SHIELD, HEAL}
+
+func (p Power) String() string {
+	if p == FREEZE {
+		return "Freeze"
+	} else if p == SHIELD {
+		return "Shield"
+	} else if p == HEAL {
+		return "Heal"
+	}
+	return ""
+}
+
+// Cooldown is how many turns, counting the moves of both sides, pass before the power can
+// be used again
+func (p Power) Cooldown() int {
+	if p == FREEZE {
+		return 6
+	} else if p == SHIELD {
+		return 8
+	} else if p == HEAL {
+		return 10
+	}
+	return 0
+}
+
+// NeedsTarget tells whether the power is used on a tile
+func (p Power) NeedsTarget() bool {
+	return p == FREEZE || p == SHIELD
+}
+
+// PowerMove is the use of a power as part of a move. The target is the tile to freeze, or
+// the tile of the player's creature to shield
+type PowerMove struct {
+	Power  Power
+	Target MapCoord
+}
+
+// WithPower returns a copy of the move that also uses a power
+func (m GameMove) WithPower(p PowerMove) GameMove {
+	return GameMove{Coords: m.Coords, Swaps: m.Swaps, Power: p}
+}
+
+func (t *Tile) IsFrozen(turn int) bool {
+	return turn < t.FrozenUntil
+}
+
+func (g *Game) powersReady(a Alignment) *[NUM_POWERS]int {
+	if a == WEST {
+		return &g.WestPowersReady
+	}
+	return &g.EastPowersReady
+}
+
+// PowerReady tells whether the side can use the power this turn
+func (g *Game) PowerReady(a Alignment, p Power) bool {
+	return g.Rules.Powers && p > NO_POWER && p < NUM_POWERS && g.TurnNumber >= g.powersReady(a)[p]
+}
+
+// TurnsUntilReady is how many more turns the side has to wait before using the power
+func (g *Game) TurnsUntilReady(a Alignment, p Power) int {
+	if g.TurnNumber >= g.powersReady(a)[p] {
+		return 0
+	}
+	return g.powersReady(a)[p] - g.TurnNumber
+}
+
+// CanUsePower tells whether the side could use the power on its target this turn
+func (g *Game) CanUsePower(a Alignment, p PowerMove) bool {
+	if !g.PowerReady(a, p.Power) {
+		return false
+	}
+	if p.Power == FREEZE {
+		return g.Map.Tile(p.Target.X, p.Target.Y) != nil
+	} else if p.Power == SHIELD {
+		return g.shieldTarget(a, p.Target) != nil
+	} else if p.Power == HEAL {
+		return g.Health(a) < g.maxHealth(a)
+	}
+	return false
+}
+
+// shieldTarget finds the creature of the side on the tile that a shield can be put on
+func (g *Game) shieldTarget(a Alignment, c MapCoord) *Creature {
+	for _, creature := range g.Creatures(a) {
+		if !creature.Removed && !creature.Shielded && creature.X == c.X && creature.Y == c.Y {
+			return creature
+		}
+	}
+	return nil
+}
+
+func (g *Game) maxHealth(a Alignment) int {
+	return STARTING_HEALTH + g.Rules.Handicap(a).ExtraHealth
+}
+
+// usePower resolves the power of the move if it is ready and has a valid target. The
+// USE_POWER event keeps the turn the power was ready before so that it can be undone
+func (g *Game) usePower(p PowerMove, side Alignment, events []GameEvent) []GameEvent {
+	if !g.CanUsePower(side, p) {
+		return events
+	}
+
+	var effect GameEvent
+	if p.Power == FREEZE {
+		tile := g.Map.Tile(p.Target.X, p.Target.Y)
+		effect = GameEvent{
+			EventType: FREEZE_TILE,
+			SourceX:   tile.X,
+			SourceY:   tile.Y,
+			Value:     tile.FrozenUntil,
+		}
+		// frozen through the opponent's turn, which in the simultaneous variant was this
+		// round, so it's only the creatures leaving it that are held back
+		tile.FrozenUntil = g.TurnNumber + g.turnsBetweenMoves()
+	} else if p.Power == SHIELD {
+		c := g.shieldTarget(side, p.Target)
+		c.Shielded = true
+		effect = GameEvent{
+			EventType:      SHIELD_CREATURE,
+			TargetCreature: c,
+		}
+	} else if p.Power == HEAL {
+		amount := g.maxHealth(side) - g.Health(side)
+		if amount > HEAL_AMOUNT {
+			amount = HEAL_AMOUNT
+		}
+		*g.health(side) += amount
+		effect = GameEvent{
+			EventType: HEAL_BASE,
+			TargetX:   int(side),
+			Value:     amount,
+		}
+	}
+
+	ready := g.powersReady(side)
+	events = append(events, GameEvent{
+		EventType: USE_POWER,
+		SourceX:   int(side),
+		TargetX:   int(p.Power),
+		Value:     ready[p.Power],
+	})
+	ready[p.Power] = g.TurnNumber + p.Power.Cooldown()
+	return append(events, effect)
+}
+
+// GeneratePowerMoves lists every way the current side can use a power this turn
+func (g *Game) GeneratePowerMoves() []PowerMove {
+	side := g.CurrentTurn
+	moves := make([]PowerMove, 0)
+	if g.PowerReady(side, FREEZE) {
+		for _, c := range g.AllCoords {
+			if !g.Map.Tiles[c.X][c.Y].IsFrozen(g.TurnNumber) {
+				moves = append(moves, PowerMove{Power: FREEZE, Target: c})
+			}
+		}
+	}
+	if g.PowerReady(side, SHIELD) {
+		for _, c := range g.Creatures(side) {
+			if !c.Removed && !c.Shielded && !IsOffMap(c.X) {
+				moves = append(moves, PowerMove{Power: SHIELD, Target: MapCoord{c.X, c.Y}})
+			}
+		}
+	}
+	if g.PowerReady(side, HEAL) && g.Health(side) < g.maxHealth(side) {
+		moves = append(moves, PowerMove{Power: HEAL})
+	}
+	return moves
+}
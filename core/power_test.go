@@ -0,0 +1,33 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+func TestFreeze(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.Powers = true
+	game := core.NewGameWithRules(7, rules)
+	c := game.EmptyCoords()[0]
+	tile := game.Map.Tiles[c.X][c.Y]
+	game.AcceptMove(core.GameMove{}.WithPower(core.PowerMove{Power: core.FREEZE, Target: c}))
+	if !tile.IsFrozen(game.TurnNumber) || tile.IsFrozen(game.TurnNumber+1) {
+		t.Fatalf("frozen until turn %d, expected only the opponent's turn %d", tile.FrozenUntil, game.TurnNumber)
+	}
+
+	// both sides move every round, so the tile is free again by the next one
+	rules.Simultaneous = true
+	game = core.NewGameWithRules(7, rules)
+	tile = game.Map.Tiles[c.X][c.Y]
+	game.AcceptSimultaneousMoves(core.GameMove{}.WithPower(core.PowerMove{Power: core.FREEZE, Target: c}), core.GameMove{})
+	if tile.IsFrozen(game.TurnNumber) {
+		t.Fatalf("frozen until turn %d, through the next round", tile.FrozenUntil)
+	}
+	reversed := tile.Reversed
+	game.AcceptSimultaneousMoves(core.GameMove{}, core.NewGameMove(c))
+	if tile.Reversed == reversed {
+		t.Fatal("the opponent can't reverse the tile the round after it was frozen")
+	}
+}
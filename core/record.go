@@ -0,0 +1,94 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// GameRecord holds everything needed to replay a game from the start. In the simultaneous
+// variant the moves alternate between the EAST and WEST moves of each round
+type GameRecord struct {
+	Seed   int64
+	Rules  Rules
+	Moves  []GameMove
+	Winner Alignment
+	Result GameResult
+	// who played each side, like "Human" or "AI (Hard)". empty if nobody said
+	EastPlayer string
+	WestPlayer string
+}
+
+func NewGameRecord(g *Game) *GameRecord {
+	return &GameRecord{
+		Seed:  g.Seed,
+		Rules: g.Rules,
+		Moves: make([]GameMove, 0),
+	}
+}
+
+func (r *GameRecord) AddMove(m GameMove) {
+	r.Moves = append(r.Moves, m)
+}
+
+// AddEvents looks for the end of the game in the events produced by a move
+func (r *GameRecord) AddEvents(events []GameEvent) {
+	for _, e := range events {
+		if e.EventType == GAME_OVER && r.Result == NO_RESULT {
+			r.Winner = Alignment(e.SourceX)
+			r.Result = GameResult(e.Value)
+		}
+	}
+}
+
+// Replay creates a new game from the record and plays all of the recorded moves on it
+func (r *GameRecord) Replay() *Game {
+	g := NewGameWithRules(r.Seed, r.Rules)
+	if r.Rules.Simultaneous {
+		for i := 0; i+1 < len(r.Moves); i += 2 {
+			g.AcceptSimultaneousMoves(r.Moves[i], r.Moves[i+1])
+		}
+		return g
+	}
+	for _, m := range r.Moves {
+		g.AcceptMove(m)
+	}
+	return g
+}
+
+func (r *GameRecord) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// Save writes the record to a file, see Write
+func (r *GameRecord) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadGameRecord reads a record saved by Write
+func ReadGameRecord(r io.Reader) (*GameRecord, error) {
+	record := &GameRecord{}
+	if err := json.NewDecoder(r).Decode(record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func LoadGameRecord(path string) (*GameRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadGameRecord(f)
+}
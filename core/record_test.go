@@ -0,0 +1,56 @@
+package core_test
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+func TestGameRecord(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.WestHandicap = core.Handicap{ExtraHealth: 10, ExtraPower: 1, ExtraReversals: 1, MovesFirst: true}
+	game := core.NewGameWithRules(7, rules)
+	record := core.NewGameRecord(game)
+	random := rand.New(rand.NewSource(7))
+	for i := 0; i < 30 && record.Result == core.NO_RESULT; i++ {
+		moves := game.GenerateLegalMoves()
+		m := moves[random.Intn(len(moves))]
+		record.AddMove(m)
+		record.AddEvents(game.AcceptMove(m))
+	}
+
+	var buf bytes.Buffer
+	if err := record.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := core.ReadGameRecord(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, record) {
+		t.Fatalf("read back\n%+v\nbut wrote\n%+v", read, record)
+	}
+	if err := gamesEqual(read.Replay(), game); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := record.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := core.LoadGameRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, record) {
+		t.Fatal("the saved record loads back different")
+	}
+	if _, err := core.LoadGameRecord(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Fatalf("loading a missing file gave %v", err)
+	}
+}
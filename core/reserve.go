@@ -0,0 +1,68 @@
+package core
+
+// IsReserve tells whether the creature is still waiting to enter the board
+func (c *Creature) IsReserve() bool {
+	return !c.Removed && IsOffMap(c.X)
+}
+
+// ReserveFront returns the index of the reserve creature that enters the board next in
+// the row, or -1 if the row has no reserves left
+func (g *Game) ReserveFront(side Alignment, row int) int {
+	front := -1
+	for i, c := range g.Creatures(side) {
+		if !c.IsReserve() || c.Y != row {
+			continue
+		}
+		// reserves march towards the board in the same direction as the rest of the army
+		if front == -1 || int(side)*c.X > int(side)*g.Creatures(side)[front].X {
+			front = i
+		}
+	}
+	return front
+}
+
+// swapReserves puts each swapped creature at the front of its row, and the creature that
+// was at the front in its place
+func (g *Game) swapReserves(move GameMove, side Alignment, events []GameEvent) []GameEvent {
+	creatures := g.Creatures(side)
+	for _, i := range move.Swaps {
+		if i < 0 || i >= len(creatures) || !creatures[i].IsReserve() {
+			continue
+		}
+		c := creatures[i]
+		front := g.ReserveFront(side, c.Y)
+		if front == i {
+			continue
+		}
+		f := creatures[front]
+		c.X, f.X = f.X, c.X
+		events = append(events, GameEvent{
+			EventType:      SWAP_RESERVE,
+			SourceX:        c.X,
+			SourceY:        c.Y,
+			SourceCreature: c,
+			TargetX:        f.X,
+			TargetY:        f.Y,
+			TargetCreature: f,
+		})
+	}
+	return events
+}
+
+// GenerateSwapMoves returns the move extended by each reserve swap the current side could
+// make, or nothing if reserve swaps aren't allowed or the move is already full
+func (g *Game) GenerateSwapMoves(m GameMove) []GameMove {
+	side := g.CurrentTurn
+	if !g.Rules.ReserveSwaps || m.Size() >= g.ReversalsAllowed(side) {
+		return []GameMove{}
+	}
+
+	moves := make([]GameMove, 0)
+	for i, c := range g.Creatures(side) {
+		if !c.IsReserve() || g.ReserveFront(side, c.Y) == i {
+			continue
+		}
+		moves = append(moves, m.WithSwap(i))
+	}
+	return moves
+}
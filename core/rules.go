@@ -0,0 +1,126 @@
+package core
+
+import "encoding/json"
+
+const STARTING_HEALTH = 50
+const REVERSALS_PER_TURN = 2
+
+// Handicap gives one side odds at the start of the game
+type Handicap struct {
+	ExtraHealth int
+	// added to the power of every creature of the side
+	ExtraPower     int
+	ExtraReversals int
+	MovesFirst     bool
+}
+
+// SuddenDeath escalates the game once it reaches the given turn, so that even games
+// don't stall forever
+type SuddenDeath struct {
+	// zero disables sudden death
+	Turn int
+	// base damage is multiplied by this
+	DamageMultiplier int
+	// added to the value of every power and armor effect
+	EffectBonus int
+}
+
+type Rules struct {
+	TimeControl      TimeControl
+	ReversalsPerTurn int
+	// both sides choose their reversals secretly and the round is resolved with
+	// Game.AcceptSimultaneousMoves instead of alternating turns
+	Simultaneous bool
+	EastHandicap Handicap
+	WestHandicap Handicap
+	// checked in order after every move, in addition to the bases being destroyed
+	WinConditions []WinCondition
+	SuddenDeath   SuddenDeath
+	// symmetric maps also give both sides the same creatures
+	MapSymmetry MapSymmetry
+	MapShape    MapShape
+	WorldEvents WorldEvents
+	// a side may use a reversal to swap a reserve creature with the one at the front of
+	// its row, choosing which creature enters the board next
+	ReserveSwaps bool
+	// players can use powers on top of their reversals, see Power
+	Powers bool
+	// allies that end up on the same tile combine into one creature with all of their power
+	MergeAllies bool
+	// creatures gain experience from kills and get stronger as they level up
+	Leveling bool
+	// some tile effects give armor or a shield instead of power
+	Armor bool
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		TimeControl:      UNTIMED,
+		ReversalsPerTurn: REVERSALS_PER_TURN,
+	}
+}
+
+// Reversals is the number of tiles each side may reverse per turn before handicaps
+func (r Rules) Reversals() int {
+	if r.ReversalsPerTurn <= 0 {
+		return REVERSALS_PER_TURN
+	}
+	return r.ReversalsPerTurn
+}
+
+func (r Rules) Handicap(a Alignment) Handicap {
+	if a == WEST {
+		return r.WestHandicap
+	}
+	return r.EastHandicap
+}
+
+// FirstPlayer returns the side that moves first. EAST moves first unless only WEST
+// has been given the first move.
+func (r Rules) FirstPlayer() Alignment {
+	if r.WestHandicap.MovesFirst && !r.EastHandicap.MovesFirst {
+		return WEST
+	}
+	return EAST
+}
+
+// plainRules has the fields of Rules without its methods, so that the JSON methods can
+// use the default encoding for everything but the win conditions
+type plainRules Rules
+
+// MarshalJSON saves the win conditions along with their types, so that UnmarshalJSON can
+// read them back
+func (r Rules) MarshalJSON() ([]byte, error) {
+	var tagged []taggedWinCondition
+	for _, c := range r.WinConditions {
+		t, err := tagWinCondition(c)
+		if err != nil {
+			return nil, err
+		}
+		tagged = append(tagged, t)
+	}
+	return json.Marshal(struct {
+		plainRules
+		WinConditions []taggedWinCondition
+	}{plainRules(r), tagged})
+}
+
+func (r *Rules) UnmarshalJSON(b []byte) error {
+	var decoded struct {
+		plainRules
+		WinConditions []taggedWinCondition
+	}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	*r = Rules(decoded.plainRules)
+	r.WinConditions = nil
+	for _, t := range decoded.WinConditions {
+		c, err := t.decode()
+		if err != nil {
+			return err
+		}
+		r.WinConditions = append(r.WinConditions, c)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package core
+
+// MapShape removes hexes from the full staggered rectangle of the board. Every column
+// needs at least one real tile so that creatures always have somewhere to land, and the
+// shapes used with a symmetric map should be symmetric themselves
+type MapShape struct {
+	Name  string
+	Holes []MapCoord
+}
+
+var FULL_SHAPE = MapShape{Name: "Full"}
+
+var HOLES_SHAPE = MapShape{
+	Name:  "Holes",
+	Holes: []MapCoord{{1, 3}, {3, 3}},
+}
+
+// only the middle row of the center column is left
+var NARROW_SHAPE = MapShape{
+	Name:  "Narrow",
+	Holes: []MapCoord{{2, 0}, {2, 4}},
+}
+
+var DIAMOND_SHAPE = MapShape{
+	Name:  "Diamond",
+	Holes: []MapCoord{{0, 0}, {0, 4}, {MAP_WIDTH - 1, 0}, {MAP_WIDTH - 1, 4}},
+}
+
+// HasTile tells whether the board has a tile at the coordinate
+func (s MapShape) HasTile(x, y int) bool {
+	if x < 0 || x >= MAP_WIDTH {
+		return false
+	}
+	if y < x%2 || y > 2*MAP_HEIGHT-2+x%2 || (y-x)%2 != 0 {
+		return false
+	}
+	for _, h := range s.Holes {
+		if h.X == x && h.Y == y {
+			return false
+		}
+	}
+	return true
+}
+
+// WarpY finds the row a creature ends up in after landing on row y of column x. Creatures
+// that leave the top or bottom of the board wrap around to the other side, and creatures
+// that land on a hole keep going in the direction they were moving (dy is 1 for down and
+// -1 for up) until they reach a real tile
+func (s MapShape) WarpY(x, y, dy int) int {
+	p := x % 2
+	for i := 0; i < MAP_HEIGHT; i++ {
+		// rows of the column are p, p+2, ... so wrap the index of the row
+		y = p + 2*(((y-p)/2%MAP_HEIGHT+MAP_HEIGHT)%MAP_HEIGHT)
+		if s.HasTile(x, y) {
+			return y
+		}
+		y += 2 * dy
+	}
+	return y
+}
@@ -0,0 +1,81 @@
+package core
+
+// UndoEvents reverts all of the events produced by a single call to AcceptMove or
+// AcceptSimultaneousMoves, which must be the last move accepted by the game
+func (g *Game) UndoEvents(events []GameEvent) {
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if e.EventType == WARP {
+			e.SourceCreature.X = e.SourceX
+			e.SourceCreature.Y = e.SourceY
+			g.countCreature(e.TargetX, e.TargetY, -1)
+			g.countCreature(e.SourceX, e.SourceY, 1)
+		} else if e.EventType == MOVE {
+			e.SourceCreature.X = e.SourceX
+			e.SourceCreature.Y = e.SourceY
+			if e.SourceX >= 0 && e.SourceX < MAP_WIDTH {
+				if e.SourceCreature.Removed {
+					e.SourceCreature.Removed = false
+				}
+			}
+			g.countCreature(e.TargetX, e.TargetY, -1)
+			g.countCreature(e.SourceX, e.SourceY, 1)
+		} else if e.EventType == DEAL_DAMAGE {
+			if e.TargetX == int(WEST) {
+				g.WestHealth += e.Value
+			} else if e.TargetX == int(EAST) {
+				g.EastHealth += e.Value
+			}
+		} else if e.EventType == UPDATE_POWER {
+			e.TargetCreature.Power -= e.Value
+		} else if e.EventType == DEATH {
+			e.SourceCreature.X = e.SourceX
+			e.SourceCreature.Y = e.SourceY
+			e.SourceCreature.Removed = false
+			g.countCreature(e.SourceX, e.SourceY, 1)
+			if e.SourceCreature.Alignment == EAST {
+				g.WestKills -= 1
+			} else {
+				g.EastKills -= 1
+			}
+		} else if e.EventType == REVERSE_TILE {
+			tile := g.Map.Tiles[e.SourceX][e.SourceY]
+			tile.Reversed = !tile.Reversed
+			tile.ReversedBy = Alignment(e.Value)
+		} else if e.EventType == CHANGE_EFFECT {
+			tile := g.Map.Tiles[e.SourceX][e.SourceY]
+			if e.Value == 1 {
+				tile.ReverseEffect = e.Effect
+			} else {
+				tile.ObverseEffect = e.Effect
+			}
+		} else if e.EventType == SWAP_RESERVE {
+			e.SourceCreature.X, e.TargetCreature.X = e.TargetCreature.X, e.SourceCreature.X
+		} else if e.EventType == USE_POWER {
+			g.powersReady(Alignment(e.SourceX))[e.TargetX] = e.Value
+		} else if e.EventType == FREEZE_TILE {
+			g.Map.Tiles[e.SourceX][e.SourceY].FrozenUntil = e.Value
+		} else if e.EventType == SHIELD_CREATURE {
+			e.TargetCreature.Shielded = false
+		} else if e.EventType == BREAK_SHIELD {
+			e.TargetCreature.Shielded = true
+		} else if e.EventType == HEAL_BASE {
+			*g.health(Alignment(e.TargetX)) -= e.Value
+		} else if e.EventType == MERGE {
+			e.TargetCreature.Power -= e.Value
+			e.SourceCreature.X = e.SourceX
+			e.SourceCreature.Y = e.SourceY
+			e.SourceCreature.Removed = false
+			g.countCreature(e.SourceX, e.SourceY, 1)
+		} else if e.EventType == UPDATE_ARMOR {
+			e.TargetCreature.Armor -= e.Value
+		} else if e.EventType == GAIN_EXPERIENCE {
+			e.TargetCreature.Experience -= 1
+		} else if e.EventType == GAME_OVER {
+			g.Winner = 0
+			g.Result = NO_RESULT
+		}
+	}
+	g.CurrentTurn = g.CurrentTurn.Opposite()
+	g.TurnNumber -= 1
+}
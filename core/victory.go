@@ -0,0 +1,170 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WinCondition is checked after every move once the bases have been checked. It returns
+// NO_RESULT if the game goes on, otherwise the result along with the winner, which is 0
+// for a draw.
+type WinCondition interface {
+	Check(g *Game) (Alignment, GameResult)
+	Description() string
+}
+
+// higher returns the side with the higher score, or 0 if they are tied
+func higher(east, west int) Alignment {
+	if east > west {
+		return EAST
+	} else if west > east {
+		return WEST
+	}
+	return 0
+}
+
+type HealthCondition struct{}
+
+func (c HealthCondition) Check(g *Game) (Alignment, GameResult) {
+	if g.EastHealth <= 0 && g.WestHealth <= 0 {
+		return 0, HEALTH_DEPLETED
+	} else if g.EastHealth <= 0 {
+		return WEST, HEALTH_DEPLETED
+	} else if g.WestHealth <= 0 {
+		return EAST, HEALTH_DEPLETED
+	}
+	return 0, NO_RESULT
+}
+
+func (c HealthCondition) Description() string {
+	return "Reduce the enemy's health to zero"
+}
+
+type EliminationCondition struct {
+	Count int
+}
+
+func (c EliminationCondition) Check(g *Game) (Alignment, GameResult) {
+	if g.EastKills >= c.Count || g.WestKills >= c.Count {
+		return higher(g.EastKills, g.WestKills), ELIMINATION
+	}
+	return 0, NO_RESULT
+}
+
+func (c EliminationCondition) Description() string {
+	return fmt.Sprintf("Eliminate %d enemy creatures", c.Count)
+}
+
+type TileMajorityCondition struct {
+	Turn int
+}
+
+func (c TileMajorityCondition) Check(g *Game) (Alignment, GameResult) {
+	if g.TurnNumber < c.Turn {
+		return 0, NO_RESULT
+	}
+	return higher(g.ReversedTileCount(EAST), g.ReversedTileCount(WEST)), TILE_MAJORITY
+}
+
+func (c TileMajorityCondition) Description() string {
+	return fmt.Sprintf("Hold the most reversed tiles at turn %d", c.Turn)
+}
+
+type HealthAfterTurnsCondition struct {
+	Turns int
+}
+
+func (c HealthAfterTurnsCondition) Check(g *Game) (Alignment, GameResult) {
+	if g.TurnNumber < c.Turns {
+		return 0, NO_RESULT
+	}
+	return higher(g.EastHealth, g.WestHealth), HEALTH_AFTER_TURNS
+}
+
+func (c HealthAfterTurnsCondition) Description() string {
+	return fmt.Sprintf("Have the most health after %d turns", c.Turns)
+}
+
+// taggedWinCondition is how a win condition is saved, since an interface can't be read back
+// without knowing which type it was
+type taggedWinCondition struct {
+	Type      string
+	Condition json.RawMessage
+}
+
+func tagWinCondition(c WinCondition) (taggedWinCondition, error) {
+	name := ""
+	if _, ok := c.(HealthCondition); ok {
+		name = "health"
+	} else if _, ok := c.(EliminationCondition); ok {
+		name = "elimination"
+	} else if _, ok := c.(TileMajorityCondition); ok {
+		name = "tile_majority"
+	} else if _, ok := c.(HealthAfterTurnsCondition); ok {
+		name = "health_after_turns"
+	} else {
+		return taggedWinCondition{}, fmt.Errorf("can't save win condition %T", c)
+	}
+	b, err := json.Marshal(c)
+	return taggedWinCondition{Type: name, Condition: b}, err
+}
+
+func (t taggedWinCondition) decode() (WinCondition, error) {
+	if t.Type == "health" {
+		var c HealthCondition
+		err := json.Unmarshal(t.Condition, &c)
+		return c, err
+	} else if t.Type == "elimination" {
+		var c EliminationCondition
+		err := json.Unmarshal(t.Condition, &c)
+		return c, err
+	} else if t.Type == "tile_majority" {
+		var c TileMajorityCondition
+		err := json.Unmarshal(t.Condition, &c)
+		return c, err
+	} else if t.Type == "health_after_turns" {
+		var c HealthAfterTurnsCondition
+		err := json.Unmarshal(t.Condition, &c)
+		return c, err
+	}
+	return nil, fmt.Errorf("unknown win condition %q", t.Type)
+}
+
+// ReversedTileCount counts the reversed tiles that were last reversed by the side
+func (g *Game) ReversedTileCount(a Alignment) int {
+	count := 0
+	for _, c := range g.AllCoords {
+		t := g.Map.Tiles[c.X][c.Y]
+		if t.Reversed && t.ReversedBy == a {
+			count += 1
+		}
+	}
+	return count
+}
+
+func (g *Game) IsOver() bool {
+	return g.Result != NO_RESULT
+}
+
+func (g *Game) checkWinConditions(events []GameEvent) []GameEvent {
+	if g.IsOver() {
+		return events
+	}
+
+	winner, result := HealthCondition{}.Check(g)
+	for i := 0; i < len(g.Rules.WinConditions) && result == NO_RESULT; i++ {
+		winner, result = g.Rules.WinConditions[i].Check(g)
+	}
+	if result == NO_RESULT {
+		return events
+	}
+
+	g.Winner = winner
+	g.Result = result
+	return append(events, GameEvent{
+		EventType: GAME_OVER,
+		SourceX:   int(winner),
+		TargetX:   int(winner.Opposite()),
+		Value:     int(result),
+	})
+}
@@ -0,0 +1,57 @@
+package core_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// saving rules has to remember which type each win condition was to read it back
+func TestWinConditionsJSON(t *testing.T) {
+	conditions := []core.WinCondition{
+		core.HealthCondition{},
+		core.EliminationCondition{Count: 6},
+		core.TileMajorityCondition{Turn: 40},
+		core.HealthAfterTurnsCondition{Turns: 30},
+	}
+	for _, c := range conditions {
+		rules := core.DefaultRules()
+		rules.WinConditions = []core.WinCondition{c}
+		b, err := json.Marshal(rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var read core.Rules
+		if err := json.Unmarshal(b, &read); err != nil {
+			t.Fatalf("%T: %s", c, err)
+		}
+		if !reflect.DeepEqual(read, rules) {
+			t.Fatalf("%T: read back %+v from %s", c, read, b)
+		}
+	}
+
+	// and in a record, all at once
+	rules := core.DefaultRules()
+	rules.WinConditions = conditions
+	record := core.NewGameRecord(core.NewGameWithRules(3, rules))
+	var buf bytes.Buffer
+	if err := record.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := core.ReadGameRecord(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, record) {
+		t.Fatalf("read back\n%+v\nbut wrote\n%+v", read, record)
+	}
+
+	bad := `{"Rules": {"WinConditions": [{"Type": "king_of_the_hill", "Condition": {}}]}}`
+	if _, err := core.ReadGameRecord(strings.NewReader(bad)); err == nil {
+		t.Fatal("read a record with an unknown win condition")
+	}
+}
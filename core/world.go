@@ -0,0 +1,134 @@
+package core
+
+import "math/rand"
+
+type WorldEventKind int
+
+const (
+	// the effects of every tile in a random column are rolled again
+	REROLL_COLUMN WorldEventKind = iota
+	// the obverse and reverse effects of every tile swap places
+	SWAP_SIDES
+)
+
+func (k WorldEventKind) String() string {
+	if k == REROLL_COLUMN {
+		return "The ground shifts!"
+	} else if k == SWAP_SIDES {
+		return "The world turns upside down!"
+	}
+	return ""
+}
+
+// WorldEvents change the tiles of the board while the game is going on
+type WorldEvents struct {
+	// an event happens at the start of every turn that is a multiple of this. zero
+	// disables scheduled events
+	Every int
+	// percent chance of an event at the start of any other turn
+	Chance int
+	// the kinds of event to pick from. all of them if empty
+	Kinds []WorldEventKind
+}
+
+var allWorldEventKinds = []WorldEventKind{REROLL_COLUMN, SWAP_SIDES}
+
+// worldSource is a splitmix64 generator. unlike the sources in math/rand it is cheap to
+// create one for every turn the search looks at
+type worldSource uint64
+
+func (s *worldSource) Seed(seed int64) {
+	*s = worldSource(seed)
+}
+
+func (s *worldSource) Int63() int64 {
+	*s += 0x9e3779b97f4a7c15
+	return int64(mix(uint64(*s)) >> 1)
+}
+
+func mix(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// worldSeed mixes the game seed with the turn. world events only depend on this, so that
+// replays see the same events and searching and undoing moves doesn't change them
+func (g *Game) worldSeed(turn int) uint64 {
+	return mix(uint64(g.Seed) + uint64(turn+1)*0x9e3779b97f4a7c15)
+}
+
+func (g *Game) worldEvents(events []GameEvent) []GameEvent {
+	w := g.Rules.WorldEvents
+	seed := g.worldSeed(g.TurnNumber)
+	scheduled := w.Every > 0 && g.TurnNumber > 0 && g.TurnNumber%w.Every == 0
+	if !scheduled && (w.Chance <= 0 || int(seed%100) >= w.Chance) {
+		return events
+	}
+
+	source := worldSource(seed)
+	random := rand.New(&source)
+	kinds := w.Kinds
+	if len(kinds) == 0 {
+		kinds = allWorldEventKinds
+	}
+	kind := kinds[random.Intn(len(kinds))]
+
+	if kind == REROLL_COLUMN {
+		column := random.Intn(MAP_WIDTH)
+		events = append(events, GameEvent{
+			EventType: WORLD_EVENT,
+			SourceX:   column,
+			Value:     int(kind),
+		})
+		for _, c := range g.AllCoords {
+			if c.X != column {
+				continue
+			}
+			tile := g.Map.Tiles[c.X][c.Y]
+			events = g.changeEffect(tile, false, g.randomEffect(c.X, c.Y, random), events)
+			events = g.changeEffect(tile, true, g.randomEffect(c.X, c.Y, random), events)
+		}
+	} else if kind == SWAP_SIDES {
+		events = append(events, GameEvent{
+			EventType: WORLD_EVENT,
+			SourceX:   -1,
+			Value:     int(kind),
+		})
+		for _, c := range g.AllCoords {
+			tile := g.Map.Tiles[c.X][c.Y]
+			obverse := tile.ObverseEffect
+			events = g.changeEffect(tile, false, tile.ReverseEffect, events)
+			events = g.changeEffect(tile, true, obverse, events)
+		}
+	}
+	return events
+}
+
+// the effect of the event is the one being replaced, and the value is 1 for the reverse
+// side of the tile
+func (g *Game) changeEffect(tile *Tile, reverse bool, e Effect, events []GameEvent) []GameEvent {
+	event := GameEvent{
+		EventType: CHANGE_EFFECT,
+		SourceX:   tile.X,
+		SourceY:   tile.Y,
+		Effect:    tile.ObverseEffect,
+	}
+	if reverse {
+		event.Effect = tile.ReverseEffect
+		event.Value = 1
+		tile.ReverseEffect = e
+	} else {
+		tile.ObverseEffect = e
+	}
+	return append(events, event)
+}
+
+// randomEffect rolls an effect the way the map was rolled for the rules of the game
+func (g *Game) randomEffect(x, y int, random *rand.Rand) Effect {
+	e := RandomEffect(x, y, random)
+	if g.Rules.Armor {
+		e = ArmorEffect(e, random)
+	}
+	return e
+}
@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/prizelobby/reverset-raiders/core"
+)
+
+// tileEffects lists the obverse and reverse effects of every tile
+func tileEffects(g *core.Game) []core.Effect {
+	effects := make([]core.Effect, 0, 2*len(g.AllCoords))
+	for _, c := range g.AllCoords {
+		effects = append(effects, g.Map.Tiles[c.X][c.Y].ObverseEffect, g.Map.Tiles[c.X][c.Y].ReverseEffect)
+	}
+	return effects
+}
+
+func TestWorldEvents(t *testing.T) {
+	rules := core.DefaultRules()
+	rules.WorldEvents = core.WorldEvents{Every: 2, Chance: 20}
+	game := core.NewGameWithRules(5, rules)
+	record := core.NewGameRecord(game)
+	random := rand.New(rand.NewSource(5))
+
+	worldEvents := 0
+	for i := 0; i < 30 && !game.IsOver(); i++ {
+		effects := tileEffects(game)
+		moves := game.GenerateLegalMoves()
+		m := moves[random.Intn(len(moves))]
+		events := game.AcceptMove(m)
+		for _, e := range events {
+			if e.EventType == core.WORLD_EVENT {
+				worldEvents += 1
+			}
+		}
+
+		// undoing the move should bring back the old effects
+		game.UndoEvents(events)
+		if !reflect.DeepEqual(effects, tileEffects(game)) {
+			t.Fatalf("tiles have different effects after undoing turn %d", i)
+		}
+
+		game.AcceptMove(m)
+		record.AddMove(m)
+	}
+	if worldEvents < 15 {
+		t.Fatalf("expected an event at least every other turn, got %d", worldEvents)
+	}
+
+	if !reflect.DeepEqual(tileEffects(game), tileEffects(record.Replay())) {
+		t.Fatalf("tiles have different effects in the replay")
+	}
+}
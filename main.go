@@ -1,9 +1,15 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/prizelobby/reverset-raiders/ai"
 	"github.com/prizelobby/reverset-raiders/core"
 	"github.com/prizelobby/reverset-raiders/res"
 	"github.com/prizelobby/reverset-raiders/scene"
@@ -17,6 +23,8 @@ const (
 	MENU GameState = iota
 	PLAYING
 	CREDITS
+	OPTIONS
+	GENERATING
 )
 
 type EbitenGame struct {
@@ -24,7 +32,12 @@ type EbitenGame struct {
 	gameState    GameState
 	MenuScene    *scene.MenuScene
 	CreditsScene *scene.CreditsScene
+	OptionsScene *scene.OptionsScene
 	GameScene    *scene.GameScene
+	// finds a balanced map before the game starts, see OptionsScene
+	GeneratingScene *scene.GeneratingScene
+	// where the records of finished games go, empty to not save them
+	RecordsDir string
 }
 
 func (g *EbitenGame) SetGameState(s string) {
@@ -32,20 +45,62 @@ func (g *EbitenGame) SetGameState(s string) {
 		g.gameState = CREDITS
 	} else if s == "menu" {
 		g.gameState = MENU
+	} else if s == "options" {
+		g.gameState = OPTIONS
 	} else if s == "playing" {
-		game := core.NewGame()
-		g.GameScene = scene.NewGameScene(game, g.SetGameState)
-		g.gameState = PLAYING
+		settings := g.OptionsScene.Settings()
+		seed := time.Now().UnixNano()
+		if settings.BalancedMap {
+			g.GeneratingScene = scene.NewGeneratingScene(settings.Rules, seed, func(seed int64) {
+				g.startGame(settings, seed)
+			}, g.SetGameState)
+			g.gameState = GENERATING
+		} else {
+			g.startGame(settings, seed)
+		}
 	}
 }
 
+func (g *EbitenGame) startGame(settings scene.GameSettings, seed int64) {
+	game := core.NewGameWithRules(seed, settings.Rules)
+	east := scene.NewPlayer(settings.EastPlayer, settings, game, 2)
+	west := scene.NewPlayer(settings.WestPlayer, settings, game, 1)
+	g.GameScene = scene.NewGameScene(game, east, west, g.SetGameState)
+	g.GameScene.Record.EastPlayer = scene.PlayerName(settings.EastPlayer, settings)
+	g.GameScene.Record.WestPlayer = scene.PlayerName(settings.WestPlayer, settings)
+	g.GameScene.OnGameOver = g.saveRecord
+	g.gameState = PLAYING
+}
+
+// saveRecord writes the record of a finished game to the records directory. Failing to
+// save it shouldn't stop the game, so errors are only logged
+func (g *EbitenGame) saveRecord(r *core.GameRecord) {
+	if g.RecordsDir == "" {
+		return
+	}
+	if err := os.MkdirAll(g.RecordsDir, 0755); err != nil {
+		log.Printf("can't save the game record: %v", err)
+		return
+	}
+	path := filepath.Join(g.RecordsDir, fmt.Sprintf("game-%s-%d.json", time.Now().Format("20060102-150405"), r.Seed))
+	if err := r.Save(path); err != nil {
+		log.Printf("can't save the game record: %v", err)
+		return
+	}
+	log.Printf("saved the game record to %s", path)
+}
+
 func (g *EbitenGame) Update() error {
 	if g.gameState == MENU {
 		g.MenuScene.Update()
 	} else if g.gameState == CREDITS {
 		g.CreditsScene.Update()
+	} else if g.gameState == OPTIONS {
+		g.OptionsScene.Update()
 	} else if g.gameState == PLAYING {
 		g.GameScene.Update()
+	} else if g.gameState == GENERATING {
+		g.GeneratingScene.Update()
 	}
 	return nil
 }
@@ -60,8 +115,12 @@ func (g *EbitenGame) Draw(screen *ebiten.Image) {
 		g.MenuScene.Draw(g.ScaledScreen)
 	} else if g.gameState == CREDITS {
 		g.CreditsScene.Draw(g.ScaledScreen)
+	} else if g.gameState == OPTIONS {
+		g.OptionsScene.Draw(g.ScaledScreen)
 	} else if g.gameState == PLAYING {
 		g.GameScene.Draw(g.ScaledScreen)
+	} else if g.gameState == GENERATING {
+		g.GeneratingScene.Draw(g.ScaledScreen)
 	}
 }
 
@@ -77,6 +136,18 @@ func (g *EbitenGame) LayoutF(outsideWidth, outsideHeight float64) (screenWidth,
 }
 
 func main() {
+	weightsFile := flag.String("weights", "", "file with the weights of the AI's evaluation, see ai.ReadWeights")
+	recordsDir := flag.String("records", "", "directory to save the records of finished games to, they aren't saved if empty")
+	flag.Parse()
+	weights := ai.DEFAULT_WEIGHTS
+	if *weightsFile != "" {
+		w, err := ai.LoadWeights(*weightsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		weights = w
+	}
+
 	// create a new text renderer and configure it
 	txtRenderer := etxt.NewStdRenderer()
 	glyphsCache := etxt.NewDefaultCache(10 * 1024 * 1024) // 10MB
@@ -89,9 +160,12 @@ func main() {
 
 	g := &EbitenGame{
 		ScaledScreen: scaledScreen,
+		RecordsDir:   *recordsDir,
 	}
 	g.MenuScene = scene.NewMenuScene(g.SetGameState)
 	g.CreditsScene = scene.NewCreditsScene(g.SetGameState)
+	g.OptionsScene = scene.NewOptionsScene(g.SetGameState)
+	g.OptionsScene.Weights = weights
 
 	ebiten.SetWindowSize(960, 480)
 	ebiten.SetWindowTitle("Hello, World!")
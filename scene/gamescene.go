@@ -1,13 +1,16 @@
 package scene
 
 import (
+	"context"
+	"fmt"
 	"image/color"
 	"math"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"github.com/prizelobby/reverset-raiders/ai"
 	"github.com/prizelobby/reverset-raiders/core"
 	"github.com/prizelobby/reverset-raiders/res"
 	"github.com/prizelobby/reverset-raiders/ui"
@@ -28,6 +31,17 @@ const EAST_HEALTH_Y = 360
 const WEST_HEALTH_X = 860
 const WEST_HEALTH_Y = 360
 
+const CLOCK_Y = 400
+
+const GOAL_TEXT_Y = 20
+
+const RESERVES_Y = 100
+const RESERVES_LINE_HEIGHT = 15
+const RESERVES_WIDTH = 120
+const RESERVES_SHOWN = 11
+const EAST_RESERVES_X = 20
+const WEST_RESERVES_X = 825
+
 const HELP_TEXT_X_CENTER = 480
 const HELP_TEXT_Y_CENTER = 440
 
@@ -44,6 +58,9 @@ type GameScene struct {
 	//hoverTileX      int
 	//hoverTileY      int
 	selectedCoords    []core.MapCoord
+	selectedSwap      int // reserve to bring to the front of its row, or -1
+	selectedPower     core.PowerMove
+	powerTargeted     bool
 	Game              *core.Game
 	SwitchSceneFunc   func(string)
 	UIState           GameUIState
@@ -53,20 +70,39 @@ type GameScene struct {
 	EffectSprites     []*ui.EffectSprite
 	TileSprites       [][]*ui.TileSprite
 	SplatSprite       *ui.SplatSprite
+	Banner            *ui.BannerSprite
 	CreatureSpriteMap map[*core.Creature]*ui.CreatureSprite
 	GameOverPane      *ui.GameOverPane
-	MoveChan          chan core.GameMove
-	Agent             *ai.Agent
+	MoveChan          chan PlayerMove
+	EastPlayer        core.Player
+	WestPlayer        core.Player
+	Record            *core.GameRecord
+	// called with the record once the game is over
+	OnGameOver func(*core.GameRecord)
+	// the sides that haven't chosen their move yet this turn, and the moves that are in
+	pendingSides []core.Alignment
+	chosenMoves  map[core.Alignment]core.GameMove
+	cancelTurn   context.CancelFunc
+	// the side that moves are selected for with the mouse
+	inputSide core.Alignment
 }
 
-func NewGameScene(game *core.Game, f func(string)) *GameScene {
+func NewGameScene(game *core.Game, east, west core.Player, f func(string)) *GameScene {
+	// holes in the board don't get a sprite
 	tileSprites := make([][]*ui.TileSprite, core.MAP_WIDTH)
 	for i := 0; i < core.MAP_WIDTH; i++ {
-		j := i % 2
-		tileSprites[i] = make([]*ui.TileSprite, 2*core.MAP_HEIGHT-1+j)
-		for ; j < 2*core.MAP_HEIGHT; j += 2 {
-			x, y := HexIndicesToScreenCoord(i, j)
-			tileSprites[i][j] = ui.NewTileSprite(x, y, game.Map.Tiles[i][j])
+		tileSprites[i] = make([]*ui.TileSprite, 2*core.MAP_HEIGHT-1+i%2)
+	}
+	for _, c := range game.AllCoords {
+		x, y := HexIndicesToScreenCoord(c.X, c.Y)
+		tileSprites[c.X][c.Y] = ui.NewTileSprite(x, y, game.Map.Tiles[c.X][c.Y])
+	}
+
+	for _, wc := range game.Rules.WinConditions {
+		if _, ok := wc.(core.TileMajorityCondition); ok {
+			for _, c := range game.AllCoords {
+				tileSprites[c.X][c.Y].ShowOwner = true
+			}
 		}
 	}
 
@@ -85,17 +121,27 @@ func NewGameScene(game *core.Game, f func(string)) *GameScene {
 	creatureSprites = append(creatureSprites, s2)
 	creatureMap[wc] = s2
 
-	return &GameScene{
+	g := &GameScene{
 		Game:              game,
 		SwitchSceneFunc:   f,
 		selectedCoords:    make([]core.MapCoord, 0, 3),
+		selectedSwap:      -1,
 		TileSprites:       tileSprites,
 		CreatureSprites:   creatureSprites,
 		CreatureSpriteMap: creatureMap,
 		GameOverPane:      &ui.GameOverPane{},
-		MoveChan:          make(chan core.GameMove),
-		Agent:             ai.NewAgent(game.Seed, 1),
+		MoveChan:          make(chan PlayerMove, 2), // room for both moves of a round
+		EastPlayer:        east,
+		WestPlayer:        west,
+		Record:            core.NewGameRecord(game),
+		inputSide:         core.EAST,
 	}
+	if !g.IsHuman(core.EAST) && g.IsHuman(core.WEST) {
+		g.inputSide = core.WEST
+	}
+
+	g.StartTurn()
+	return g
 }
 
 func (g *GameScene) MouseCoordsToTileCoords(x, y float64) (int, int) {
@@ -159,13 +205,8 @@ func (g *GameScene) IndexOfSelectedCoord(i, j int) int {
 }
 
 func (g *GameScene) IsValidTileCoordsForTurn(i, j int) bool {
-	if i < 0 || i >= core.MAP_WIDTH {
-		return false
-	}
-	if j < 0 || j >= len(g.Game.Map.Tiles[i]) {
-		return false
-	}
-	return !g.Game.Map.Tiles[i][j].HasCreature
+	t := g.Game.Map.Tile(i, j)
+	return t != nil && !t.HasCreature()
 }
 
 func (g *GameScene) IsInsideConfirmButton(x, y float64) bool {
@@ -221,7 +262,54 @@ func (g *GameScene) AnimationForEvent(e core.GameEvent) animation.Anim {
 		return animation.NewSplatAnimation(g.SplatSprite)
 	} else if e.EventType == core.GAME_OVER {
 		g.GameOverPane.Winner = core.Alignment(e.SourceX)
+		g.GameOverPane.Result = core.GameResult(e.Value)
 		g.UIState = GAME_OVER
+	} else if e.EventType == core.SUDDEN_DEATH {
+		text := "Sudden death!"
+		if g.Game.Rules.SuddenDeath.DamageMultiplier > 1 {
+			text += " Damage x" + strconv.Itoa(g.Game.Rules.SuddenDeath.DamageMultiplier)
+		}
+		if g.Game.Rules.SuddenDeath.EffectBonus > 0 {
+			text += " Effects +" + strconv.Itoa(g.Game.Rules.SuddenDeath.EffectBonus)
+		}
+		g.Banner = ui.NewBannerSprite(text)
+		return animation.NewBannerAnimation(g.Banner)
+	} else if e.EventType == core.SWAP_RESERVE {
+		// only the reserve waiting next to the board has a sprite, so it goes to the new front
+		if cs := g.CreatureSpriteMap[e.TargetCreature]; cs != nil {
+			cs.Removed = true
+			delete(g.CreatureSpriteMap, e.TargetCreature)
+			x, y := HexIndicesToScreenCoord(e.SourceX, e.SourceY)
+			s := ui.NewCreatureSprite(x+32, y+15, e.SourceCreature)
+			g.CreatureSprites = append(g.CreatureSprites, s)
+			g.CreatureSpriteMap[e.SourceCreature] = s
+		}
+	} else if e.EventType == core.USE_POWER && !g.IsHuman(core.Alignment(e.SourceX)) {
+		g.Banner = ui.NewBannerSprite(SideName(core.Alignment(e.SourceX)) + " used " + core.Power(e.TargetX).String() + "!")
+		return animation.NewBannerAnimation(g.Banner)
+	} else if e.EventType == core.SHIELD_CREATURE {
+		return animation.NewCreatureSpriteShield(g.CreatureSpriteMap[e.TargetCreature], true)
+	} else if e.EventType == core.BREAK_SHIELD {
+		return animation.NewCreatureSpriteShield(g.CreatureSpriteMap[e.TargetCreature], false)
+	} else if e.EventType == core.MERGE {
+		return animation.NewGroupAnimation(
+			animation.NewDeathAnimation(g.CreatureSpriteMap[e.SourceCreature]),
+			animation.NewCreatureSpriteUpdatePower(g.CreatureSpriteMap[e.TargetCreature], e.Value),
+		)
+	} else if e.EventType == core.UPDATE_ARMOR {
+		return animation.NewCreatureSpriteUpdateArmor(g.CreatureSpriteMap[e.TargetCreature], e.Value)
+	} else if e.EventType == core.LEVEL_UP {
+		return animation.NewCreatureSpriteLevelUp(g.CreatureSpriteMap[e.TargetCreature], e.Value)
+	} else if e.EventType == core.WORLD_EVENT {
+		// the tiles have already changed, so the CHANGE_EFFECT events don't need their own animation
+		tiles := make([]*ui.TileSprite, 0)
+		for _, c := range g.Game.AllCoords {
+			if e.SourceX == -1 || c.X == e.SourceX {
+				tiles = append(tiles, g.TileSprites[c.X][c.Y])
+			}
+		}
+		g.Banner = ui.NewBannerSprite(core.WorldEventKind(e.Value).String())
+		return animation.NewGroupAnimation(animation.NewBannerAnimation(g.Banner), animation.NewTileChangeAnimation(tiles...))
 	} else if e.EventType == core.APPLY_EFFECT {
 		g.EffectSprites = make([]*ui.EffectSprite, 0)
 		x, y := HexIndicesToScreenCoord(e.TargetCreature.X, e.TargetCreature.Y)
@@ -244,12 +332,7 @@ func (g *GameScene) UpdateAnimations() {
 	}
 
 	if len(g.EventsToAnimate) == 0 {
-		if g.Game.CurrentTurn == core.EAST {
-			g.UIState = WAITING_FOR_PLAYER_MOVE
-		} else {
-			g.UIState = WAITING_FOR_OPP_MOVE
-		}
-
+		g.StartTurn()
 	} else {
 		event := g.EventsToAnimate[0]
 		g.EventsToAnimate = g.EventsToAnimate[1:]
@@ -261,37 +344,189 @@ func (g *GameScene) UpdatePlayerActions() {
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		cx, cy := ui.AdjustedCursorPosition()
 		i, j := g.MouseCoordsToTileCoords(cx, cy)
-		if index := g.IndexOfSelectedCoord(i, j); index != -1 {
+		if p := g.PowerButtonAt(cx, cy); p != core.NO_POWER {
+			g.SelectPower(p)
+		} else if g.AwaitingPowerTarget() && g.Game.Map.Tile(i, j) != nil {
+			g.TargetPower(i, j)
+		} else if index := g.IndexOfSelectedCoord(i, j); index != -1 {
 			g.selectedCoords[index] = g.selectedCoords[len(g.selectedCoords)-1]
 			g.selectedCoords = g.selectedCoords[:len(g.selectedCoords)-1]
 			g.TileSprites[i][j].Selected = !g.TileSprites[i][j].Selected
-		} else if g.IsValidTileCoordsForTurn(i, j) && len(g.selectedCoords) < 2 {
+		} else if g.IsValidTileCoordsForTurn(i, j) && g.SelectedMove().Size() < g.Game.ReversalsAllowed(g.inputSide) {
 			g.selectedCoords = append(g.selectedCoords, core.MapCoord{X: i, Y: j})
 			g.TileSprites[i][j].Selected = !g.TileSprites[i][j].Selected
+		} else if c := g.ReserveAt(cx, cy); c != -1 {
+			g.SelectSwap(c)
 		} else if g.IsInsideConfirmButton(cx, cy) {
-			if len(g.selectedCoords) > 0 {
-				var move core.GameMove
-				move.First = g.selectedCoords[0]
-				g.TileSprites[move.First.X][move.First.Y].Selected = false
-				if len(g.selectedCoords) > 1 {
-					move.Second = g.selectedCoords[1]
-					g.TileSprites[move.Second.X][move.Second.Y].Selected = false
-				} else {
-					move.Second = core.MapCoord{X: -1, Y: -1}
+			if g.HasSelection() {
+				move := g.SelectedMove()
+				// committed tiles stay selected until both moves are revealed, unless the
+				// opponent is looking at the same screen
+				for _, c := range move.Coords {
+					g.TileSprites[c.X][c.Y].Selected = g.Game.Rules.Simultaneous && !g.IsHuman(g.inputSide.Opposite())
 				}
-				g.Agent.AcceptMove(move)
-				g.EventsToAnimate = g.Game.AcceptMove(move)
-				g.selectedCoords = make([]core.MapCoord, 0, 2)
-				g.UIState = WAITING_FOR_PLAYER_ANIMIMATION
-				go func() {
-					move, _ := g.Agent.MakeMove()
-					g.MoveChan <- move
-				}()
+				g.selectedCoords = make([]core.MapCoord, 0, 3)
+				g.selectedSwap = -1
+				g.ClearPowerTarget()
+				g.selectedPower = core.PowerMove{}
+				g.SubmitMove(move)
 			}
 		}
 	}
 }
 
+// SelectedMove builds the player's move from the current selection
+func (g *GameScene) SelectedMove() core.GameMove {
+	move := core.NewGameMove(g.selectedCoords...)
+	if g.selectedSwap != -1 {
+		move = move.WithSwap(g.selectedSwap)
+	}
+	if g.selectedPower.Power != core.NO_POWER && !g.AwaitingPowerTarget() {
+		move = move.WithPower(g.selectedPower)
+	}
+	return move
+}
+
+func (g *GameScene) HasSelection() bool {
+	m := g.SelectedMove()
+	return m.Size() > 0 || m.Power.Power != core.NO_POWER
+}
+
+// ReserveQueue lists the reserves of a side in the order they enter the board
+func (g *GameScene) ReserveQueue(side core.Alignment) []int {
+	queue := make([]int, 0)
+	creatures := g.Game.Creatures(side)
+	for i, c := range creatures {
+		if c.IsReserve() {
+			queue = append(queue, i)
+		}
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		return int(side)*creatures[queue[i]].X > int(side)*creatures[queue[j]].X
+	})
+	if len(queue) > RESERVES_SHOWN {
+		queue = queue[:RESERVES_SHOWN]
+	}
+	return queue
+}
+
+func ReservesX(side core.Alignment) int {
+	if side == core.WEST {
+		return WEST_RESERVES_X
+	}
+	return EAST_RESERVES_X
+}
+
+// ReserveAt returns the reserve creature of the side choosing a move listed under the
+// cursor, or -1
+func (g *GameScene) ReserveAt(x, y float64) int {
+	rx := float64(ReservesX(g.inputSide))
+	if !g.Game.Rules.ReserveSwaps || x < rx || x > rx+RESERVES_WIDTH {
+		return -1
+	}
+	line := int(math.Floor((y - RESERVES_Y) / RESERVES_LINE_HEIGHT))
+	queue := g.ReserveQueue(g.inputSide)
+	// the first line is the heading
+	if line < 1 || line > len(queue) {
+		return -1
+	}
+	return queue[line-1]
+}
+
+// SelectSwap toggles bringing the reserve to the front of its row. Only one reserve can be
+// swapped per turn, and the one at the front already enters next
+func (g *GameScene) SelectSwap(c int) {
+	if g.selectedSwap == c {
+		g.selectedSwap = -1
+		return
+	}
+	if g.Game.ReserveFront(g.inputSide, g.Game.Creatures(g.inputSide)[c].Y) == c {
+		return
+	}
+	if g.selectedSwap == -1 && g.SelectedMove().Size() >= g.Game.ReversalsAllowed(g.inputSide) {
+		return
+	}
+	g.selectedSwap = c
+}
+
+func (g *GameScene) ApplyMove(m core.GameMove) []core.GameEvent {
+	events := g.Game.AcceptMove(m)
+	g.Record.AddMove(m)
+	g.recordEvents(events)
+	return events
+}
+
+// recordEvents adds the events to the record, handing it over once the game is over
+func (g *GameScene) recordEvents(events []core.GameEvent) {
+	over := g.Record.Result != core.NO_RESULT
+	g.Record.AddEvents(events)
+	if !over && g.Record.Result != core.NO_RESULT && g.OnGameOver != nil {
+		g.OnGameOver(g.Record)
+	}
+}
+
+// RevealMoves shows both moves of the round and resolves them
+func (g *GameScene) RevealMoves(east, west core.GameMove) {
+	tiles := make([]*ui.TileSprite, 0, len(east.Coords)+len(west.Coords))
+	for _, c := range append(append([]core.MapCoord{}, east.Coords...), west.Coords...) {
+		tiles = append(tiles, g.TileSprites[c.X][c.Y])
+	}
+	g.OngoingAnimation = animation.NewTileHighlightAnimation(tiles...)
+
+	g.EventsToAnimate = g.Game.AcceptSimultaneousMoves(east, west)
+	g.Record.AddMove(east)
+	g.Record.AddMove(west)
+	g.recordEvents(g.EventsToAnimate)
+	g.UIState = WAITING_FOR_PLAYER_ANIMIMATION
+}
+
+// StopClock stops the running clock and ends the game if the side ran out of time.
+func (g *GameScene) StopClock() bool {
+	if g.Game.Clock == nil || g.Game.Clock.Running == 0 {
+		return false
+	}
+	side := g.Game.Clock.Running
+	if g.Game.Clock.Stop(time.Now()) {
+		g.cancelTurn()
+		g.EventsToAnimate = g.Game.LoseOnTime(side)
+		g.recordEvents(g.EventsToAnimate)
+		g.UIState = WAITING_FOR_PLAYER_ANIMIMATION
+		return true
+	}
+	return false
+}
+
+func (g *GameScene) CheckFlag() bool {
+	if g.Game.Clock == nil || g.Game.Clock.Running == 0 {
+		return false
+	}
+	if g.Game.Clock.IsFlagged(g.Game.Clock.Running, time.Now()) {
+		return g.StopClock()
+	}
+	return false
+}
+
+func FormatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func (g *GameScene) ClockText(side core.Alignment) string {
+	c := g.Game.Clock
+	now := time.Now()
+	if c.Control.Total == 0 {
+		return FormatClock(c.MoveTimeLeft(side, now))
+	}
+	text := FormatClock(c.TotalLeft(side, now))
+	if c.Control.PerMove > 0 && c.Running == side {
+		text += " (" + FormatClock(c.MoveTimeLeft(side, now)) + ")"
+	}
+	return text
+}
+
 func (g *GameScene) UpdateGameOverActions() {
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 		cx, cy := ui.AdjustedCursorPosition()
@@ -305,31 +540,28 @@ func (g *GameScene) Update() {
 
 	if g.UIState == WAITING_FOR_PLAYER_ANIMIMATION {
 		g.UpdateAnimations()
-	} else if g.UIState == WAITING_FOR_PLAYER_MOVE {
-		g.UpdatePlayerActions()
-	} else if g.UIState == WAITING_FOR_OPP_MOVE {
+	} else if g.UIState == WAITING_FOR_PLAYER_MOVE || g.UIState == WAITING_FOR_OPP_MOVE {
+		if g.CheckFlag() {
+			return
+		}
 		select {
 		case m := <-g.MoveChan:
-			t1 := g.TileSprites[m.First.X][m.First.Y]
-			var t2 *ui.TileSprite = nil
-			if m.Second.X > 0 {
-				t2 = g.TileSprites[m.Second.X][m.Second.Y]
-			}
-			g.OngoingAnimation = animation.NewTileHighlightAnimation(t1, t2)
-			g.EventsToAnimate = g.Game.AcceptMove(m)
-			g.UIState = WAITING_FOR_PLAYER_ANIMIMATION
+			g.ReceiveMove(m)
+			return
 		default:
 		}
+		if g.UIState == WAITING_FOR_PLAYER_MOVE {
+			g.UpdatePlayerActions()
+		}
 	} else if g.UIState == GAME_OVER {
 		g.UpdateGameOverActions()
 	}
 }
 
 func (g *GameScene) Draw(screen *ui.ScaledScreen) {
-	for i := 0; i < core.MAP_WIDTH; i++ {
-		for j := i % 2; j < 2*core.MAP_HEIGHT; j += 2 {
-			g.TileSprites[i][j].Draw(screen)
-		}
+	for _, c := range g.Game.AllCoords {
+		g.TileSprites[c.X][c.Y].Frozen = g.Game.Map.Tiles[c.X][c.Y].IsFrozen(g.Game.TurnNumber)
+		g.TileSprites[c.X][c.Y].Draw(screen)
 	}
 	for _, cs := range g.CreatureSprites {
 		cs.Draw(screen)
@@ -341,7 +573,7 @@ func (g *GameScene) Draw(screen *ui.ScaledScreen) {
 
 	cOpts := &ebiten.DrawImageOptions{}
 	cOpts.GeoM.Translate(CONFIRM_BUTTON_X_FLOAT, CONFIRM_BUTTON_Y_FLOAT)
-	if len(g.selectedCoords) > 0 {
+	if g.HasSelection() {
 		screen.DrawImage(res.GetImage("confirmmove"), cOpts)
 	} else {
 		screen.DrawImage(res.GetImage("confirmmoveinactive"), cOpts)
@@ -352,48 +584,44 @@ func (g *GameScene) Draw(screen *ui.ScaledScreen) {
 	screen.DrawTextCenteredAt(strconv.Itoa(g.Game.EastHealth), 32, EAST_HEALTH_X, EAST_HEALTH_Y, color.RGBA{0xac, 0x32, 0x32, 0xff})
 	screen.DrawTextCenteredAt(strconv.Itoa(g.Game.WestHealth), 32, WEST_HEALTH_X, WEST_HEALTH_Y, color.RGBA{0xac, 0x32, 0x32, 0xff})
 
-	if g.UIState == WAITING_FOR_PLAYER_MOVE {
-		screen.DrawTextCenteredAt("Select up to 2 tiles to reverse.", 28, HELP_TEXT_X_CENTER, HELP_TEXT_Y_CENTER, color.White)
-	} else {
-		screen.DrawTextCenteredAt("Waiting for opponent...", 28, HELP_TEXT_X_CENTER, HELP_TEXT_Y_CENTER, color.White)
+	for i, wc := range g.Game.Rules.WinConditions {
+		screen.DrawTextCenteredAt(wc.Description(), 14, HELP_TEXT_X_CENTER, GOAL_TEXT_Y+16*i, color.White)
 	}
 
-	eastReserves := "Reserves - Row\n"
-	eCount := 0
-	for _, ec := range g.Game.EastCreatures {
-		if !ec.Removed {
-			if ec.X < 0 {
-				eCount += 1
-				eastReserves += ec.Color.String() + " " + ec.Species.String() + " - " + strconv.Itoa(1+(ec.Y/2)) + "\n"
-
-				if eCount > 10 {
-					break
-				}
-			}
-		}
+	if g.Game.Clock != nil {
+		screen.DrawTextCenteredAt(g.ClockText(core.EAST), 20, EAST_HEALTH_X, CLOCK_Y, color.White)
+		screen.DrawTextCenteredAt(g.ClockText(core.WEST), 20, WEST_HEALTH_X, CLOCK_Y, color.White)
 	}
-	screen.DrawText(eastReserves, 12, 20, 100, color.White)
 
-	westReserves := "Reserves - Row\n"
-	wCount := 0
-	for _, wc := range g.Game.WestCreatures {
-		if !wc.Removed {
-			if wc.X >= core.MAP_WIDTH {
-				wCount += 1
-				westReserves += wc.Color.String() + " " + wc.Species.String() + " - " + strconv.Itoa(1+(wc.Y/2)) + "\n"
-
-				if wCount > 10 {
-					break
-				}
-			}
-		}
+	things := " tiles to reverse."
+	if g.Game.Rules.ReserveSwaps {
+		things = " tiles or reserves."
+	}
+	// people taking turns on the same screen need to know whose turn it is
+	who := ""
+	if g.IsHuman(core.EAST) && g.IsHuman(core.WEST) {
+		who = SideName(g.inputSide) + ": "
+	}
+	if g.UIState == WAITING_FOR_PLAYER_MOVE && g.Game.Rules.Simultaneous {
+		screen.DrawTextCenteredAt(who+"Secretly select up to "+strconv.Itoa(g.Game.ReversalsAllowed(g.inputSide))+things, 28, HELP_TEXT_X_CENTER, HELP_TEXT_Y_CENTER, color.White)
+	} else if g.UIState == WAITING_FOR_PLAYER_MOVE {
+		screen.DrawTextCenteredAt(who+"Select up to "+strconv.Itoa(g.Game.ReversalsAllowed(g.inputSide))+things, 28, HELP_TEXT_X_CENTER, HELP_TEXT_Y_CENTER, color.White)
+	} else {
+		screen.DrawTextCenteredAt("Waiting for opponent...", 28, HELP_TEXT_X_CENTER, HELP_TEXT_Y_CENTER, color.White)
 	}
-	screen.DrawText(westReserves, 12, 825, 100, color.White)
+
+	g.DrawPowers(screen)
+	g.DrawReserves(screen, core.EAST, EAST_RESERVES_X)
+	g.DrawReserves(screen, core.WEST, WEST_RESERVES_X)
 
 	if g.SplatSprite != nil {
 		g.SplatSprite.Draw(screen)
 	}
 
+	if g.Banner != nil {
+		g.Banner.Draw(screen)
+	}
+
 	if g.UIState == GAME_OVER {
 		g.GameOverPane.Draw(screen)
 	}
@@ -402,3 +630,24 @@ func (g *GameScene) Draw(screen *ui.ScaledScreen) {
 var RED = []float64{0xac / 255.0, 0x32 / 255.0, 0x32 / 255.0, 1.0}
 var GREEN = []float64{0x6a / 255.0, 0xbe / 255.0, 0x30 / 255.0, 1.0}
 var BLUE = []float64{0x30 / 255.0, 0x60 / 255.0, 0x82 / 255.0, 1.0}
+
+// SideName is what the side is called on screen, matching the health labels
+func SideName(side core.Alignment) string {
+	if side == core.WEST {
+		return "Enemy"
+	}
+	return "Player"
+}
+
+func (g *GameScene) DrawReserves(screen *ui.ScaledScreen, side core.Alignment, x int) {
+	screen.DrawText("Reserves - Row", 12, x, RESERVES_Y, color.White)
+	creatures := g.Game.Creatures(side)
+	for n, i := range g.ReserveQueue(side) {
+		c := creatures[i]
+		var textColor color.Color = color.White
+		if side == g.inputSide && i == g.selectedSwap {
+			textColor = color.RGBA{0x6a, 0xbe, 0x30, 0xff}
+		}
+		screen.DrawText(c.Color.String()+" "+c.Species.String()+" - "+strconv.Itoa(1+(c.Y/2)), 12, x, RESERVES_Y+(n+1)*RESERVES_LINE_HEIGHT, textColor)
+	}
+}
@@ -0,0 +1,72 @@
+package scene
+
+import (
+	"context"
+	"image/color"
+	"log"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/prizelobby/reverset-raiders/ai"
+	"github.com/prizelobby/reverset-raiders/core"
+	"github.com/prizelobby/reverset-raiders/ui"
+)
+
+type balancedSeed struct {
+	Seed int64
+	OK   bool
+	Err  error
+}
+
+// GeneratingScene looks for a balanced map in the background, since it plays a lot of
+// games and the window would freeze otherwise
+type GeneratingScene struct {
+	SwitchSceneFunc func(string)
+	// called with the seed of the map once it's found
+	OnSeed func(int64)
+	cancel context.CancelFunc
+	done   chan balancedSeed
+}
+
+func NewGeneratingScene(rules core.Rules, seed int64, onSeed func(int64), f func(string)) *GeneratingScene {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &GeneratingScene{
+		SwitchSceneFunc: f,
+		OnSeed:          onSeed,
+		cancel:          cancel,
+		// buffered so that the search can finish even if nobody is waiting anymore
+		done: make(chan balancedSeed, 1),
+	}
+	go func() {
+		s, ok, err := ai.FindBalancedSeed(ctx, rules, ai.DEFAULT_BALANCE_CONFIG, rand.New(rand.NewSource(seed)))
+		g.done <- balancedSeed{s, ok, err}
+	}()
+	return g
+}
+
+func (g *GeneratingScene) Update() {
+	select {
+	case r := <-g.done:
+		g.cancel()
+		if r.Err != nil {
+			log.Printf("stopped looking for a balanced map: %v", r.Err)
+			g.SwitchSceneFunc("menu")
+			return
+		}
+		if !r.OK {
+			log.Printf("no map was balanced enough, playing the most balanced one")
+		}
+		g.OnSeed(r.Seed)
+	default:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+			// the search notices between games, and then the result says it was cancelled
+			g.cancel()
+		}
+	}
+}
+
+func (g *GeneratingScene) Draw(screen *ui.ScaledScreen) {
+	screen.DrawTextCenteredAt("Generating a balanced map...", 48, 480, 200, color.White)
+	screen.DrawTextCenteredAt("click anywhere to cancel", 16, 480, 450, color.White)
+}
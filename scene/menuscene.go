@@ -14,7 +14,8 @@ const VERSION_STRING = "version 1.0.1"
 
 const CENTER = 480
 const TITLE_Y_CENTER = 100
-const NEW_GAME_Y_CENTER = 300
+const NEW_GAME_Y_CENTER = 260
+const OPTIONS_Y_CENTER = 330
 const CREDITS_Y_CENTER = 400
 
 type MenuScene struct {
@@ -30,11 +31,15 @@ func NewMenuScene(switchSceneFunc func(string)) *MenuScene {
 func (m *MenuScene) Update() {
 	cursorX, cursorY := ui.AdjustedCursorPosition()
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		if math.Abs(cursorX-CENTER) < 100 && math.Abs(cursorY-300) < 50 {
+		if math.Abs(cursorX-CENTER) < 100 && math.Abs(cursorY-NEW_GAME_Y_CENTER) < 30 {
 			m.SwitchSceneFunc("playing")
 		}
 
-		if math.Abs(cursorX-CENTER) < 100 && math.Abs(cursorY-400) < 50 {
+		if math.Abs(cursorX-CENTER) < 100 && math.Abs(cursorY-OPTIONS_Y_CENTER) < 30 {
+			m.SwitchSceneFunc("options")
+		}
+
+		if math.Abs(cursorX-CENTER) < 100 && math.Abs(cursorY-CREDITS_Y_CENTER) < 30 {
 			m.SwitchSceneFunc("credits")
 		}
 	}
@@ -44,6 +49,7 @@ func (m *MenuScene) Draw(scaledScreen *ui.ScaledScreen) {
 	scaledScreen.DrawImage(res.GetImage("title"), &ebiten.DrawImageOptions{})
 	scaledScreen.DrawTextCenteredAt("Reverset Raiders", 48.0, CENTER, TITLE_Y_CENTER, color.Black)
 	scaledScreen.DrawTextCenteredAt("New Game", 32.0, CENTER, NEW_GAME_Y_CENTER, color.Black)
+	scaledScreen.DrawTextCenteredAt("Options", 32.0, CENTER, OPTIONS_Y_CENTER, color.Black)
 	scaledScreen.DrawTextCenteredAt("Credits", 32.0, CENTER, CREDITS_Y_CENTER, color.Black)
 	scaledScreen.DrawText(VERSION_STRING, 16.0, 862, 460, color.Black)
 }
@@ -0,0 +1,280 @@
+package scene
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/prizelobby/reverset-raiders/ai"
+	"github.com/prizelobby/reverset-raiders/core"
+	"github.com/prizelobby/reverset-raiders/ui"
+)
+
+const OPTIONS_START_Y = 76
+const OPTIONS_ROW_HEIGHT = 26
+const OPTIONS_ROWS_PER_COLUMN = 13
+const OPTIONS_COLUMN_WIDTH = 470
+const OPTIONS_VALUE_OFFSET = 230
+const OPTIONS_BACK_Y = 450
+
+// OptionRow is a single setting that cycles through its values when clicked
+type OptionRow struct {
+	Label    string
+	Values   []string
+	Selected int
+	Apply    func(s *GameSettings, selected int)
+}
+
+// GameSettings is everything chosen in the options that is needed to start a new game
+type GameSettings struct {
+	Rules core.Rules
+	// roll maps until the AI finds one where moving first isn't a big advantage
+	BalancedMap bool
+	EastPlayer  PlayerKind
+	WestPlayer  PlayerKind
+	// of every AI player
+	Difficulty ai.Difficulty
+	// the evaluation of every AI player
+	Weights ai.Weights
+}
+
+type OptionsScene struct {
+	SwitchSceneFunc func(string)
+	Rows            []*OptionRow
+	// there's no row for these, they're loaded from a file at startup
+	Weights ai.Weights
+}
+
+var extraHealthValues = []int{0, 10, 20, 30}
+var extraPowerValues = []int{0, 1, 2, 3}
+
+func HandicapRows(label string, side core.Alignment) []*OptionRow {
+	handicap := func(s *GameSettings) *core.Handicap {
+		if side == core.WEST {
+			return &s.Rules.WestHandicap
+		}
+		return &s.Rules.EastHandicap
+	}
+
+	return []*OptionRow{
+		{
+			Label:  label + " extra health",
+			Values: []string{"0", "10", "20", "30"},
+			Apply: func(s *GameSettings, selected int) {
+				handicap(s).ExtraHealth = extraHealthValues[selected]
+			},
+		},
+		{
+			Label:  label + " extra power",
+			Values: []string{"0", "1", "2", "3"},
+			Apply: func(s *GameSettings, selected int) {
+				handicap(s).ExtraPower = extraPowerValues[selected]
+			},
+		},
+		{
+			Label:  label + " extra reversal",
+			Values: []string{"No", "Yes"},
+			Apply: func(s *GameSettings, selected int) {
+				handicap(s).ExtraReversals = selected
+			},
+		},
+	}
+}
+
+var playerKinds = []PlayerKind{HUMAN_PLAYER, AGENT_PLAYER, RANDOM_PLAYER, MCTS_PLAYER}
+
+func NewOptionsScene(f func(string)) *OptionsScene {
+	rows := []*OptionRow{
+		{
+			Label:  "Player",
+			Values: []string{"Human", "AI", "Random", "MCTS"},
+			Apply: func(s *GameSettings, selected int) {
+				s.EastPlayer = playerKinds[selected]
+			},
+		},
+		{
+			Label:    "Enemy",
+			Values:   []string{"Human", "AI", "Random", "MCTS"},
+			Selected: 1,
+			Apply: func(s *GameSettings, selected int) {
+				s.WestPlayer = playerKinds[selected]
+			},
+		},
+		{
+			Label:    "AI difficulty",
+			Values:   []string{"Beginner", "Normal", "Hard", "Expert"},
+			Selected: 3,
+			Apply: func(s *GameSettings, selected int) {
+				s.Difficulty = ai.DIFFICULTIES[selected]
+			},
+		},
+		{
+			Label:  "Time control",
+			Values: []string{"Untimed", "Blitz", "Standard"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.TimeControl = []core.TimeControl{core.UNTIMED, core.BLITZ, core.STANDARD}[selected]
+			},
+		},
+		{
+			Label:    "Reversals per turn",
+			Values:   []string{"1", "2", "3"},
+			Selected: 1,
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.ReversalsPerTurn = selected + 1
+			},
+		},
+		{
+			Label:  "Turns",
+			Values: []string{"Alternating", "Simultaneous"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.Simultaneous = selected == 1
+			},
+		},
+		{
+			Label:  "Victory",
+			Values: []string{"Base", "Eliminate 10", "Tiles at turn 30", "Health at turn 40"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.WinConditions = [][]core.WinCondition{
+					nil,
+					{core.EliminationCondition{Count: 10}},
+					{core.TileMajorityCondition{Turn: 30}},
+					{core.HealthAfterTurnsCondition{Turns: 40}},
+				}[selected]
+			},
+		},
+		{
+			Label:  "Sudden death",
+			Values: []string{"Off", "Turn 30: damage x2", "Turn 30: effects +2"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.SuddenDeath = []core.SuddenDeath{
+					{},
+					{Turn: 30, DamageMultiplier: 2},
+					{Turn: 30, EffectBonus: 2},
+				}[selected]
+			},
+		},
+		{
+			Label:  "Map",
+			Values: []string{"Random", "Mirrored", "Point symmetric"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.MapSymmetry = core.MapSymmetry(selected)
+			},
+		},
+		{
+			Label:  "Reserve swaps",
+			Values: []string{"Off", "On"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.ReserveSwaps = selected == 1
+			},
+		},
+		{
+			Label:  "Leveling",
+			Values: []string{"Off", "On"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.Leveling = selected == 1
+			},
+		},
+		{
+			Label:  "Armor",
+			Values: []string{"Off", "On"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.Armor = selected == 1
+			},
+		},
+		{
+			Label:  "Merge allies",
+			Values: []string{"Off", "On"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.MergeAllies = selected == 1
+			},
+		},
+		{
+			Label:  "Powers",
+			Values: []string{"Off", "On"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.Powers = selected == 1
+			},
+		},
+		{
+			Label:  "World events",
+			Values: []string{"Off", "Every 5 turns", "Column re-roll", "Random"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.WorldEvents = []core.WorldEvents{
+					{},
+					{Every: 5},
+					{Every: 5, Kinds: []core.WorldEventKind{core.REROLL_COLUMN}},
+					{Chance: 10},
+				}[selected]
+			},
+		},
+		{
+			Label:  "Board",
+			Values: []string{"Full", "Holes", "Narrow", "Diamond"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.MapShape = []core.MapShape{core.FULL_SHAPE, core.HOLES_SHAPE, core.NARROW_SHAPE, core.DIAMOND_SHAPE}[selected]
+			},
+		},
+		{
+			Label:  "Balance check",
+			Values: []string{"Off", "On"},
+			Apply: func(s *GameSettings, selected int) {
+				s.BalancedMap = selected == 1
+			},
+		},
+		{
+			Label:  "First move",
+			Values: []string{"Player", "Enemy"},
+			Apply: func(s *GameSettings, selected int) {
+				s.Rules.EastHandicap.MovesFirst = selected == 0
+				s.Rules.WestHandicap.MovesFirst = selected == 1
+			},
+		},
+	}
+	rows = append(rows, HandicapRows("Player", core.EAST)...)
+	rows = append(rows, HandicapRows("Enemy", core.WEST)...)
+
+	return &OptionsScene{
+		SwitchSceneFunc: f,
+		Rows:            rows,
+		Weights:         ai.DEFAULT_WEIGHTS,
+	}
+}
+
+// Settings builds the settings for a new game from the selected options
+func (o *OptionsScene) Settings() GameSettings {
+	s := GameSettings{Rules: core.DefaultRules(), Weights: o.Weights}
+	for _, row := range o.Rows {
+		row.Apply(&s, row.Selected)
+	}
+	return s
+}
+
+func RowPosition(i int) (int, int) {
+	return 20 + (i/OPTIONS_ROWS_PER_COLUMN)*OPTIONS_COLUMN_WIDTH, OPTIONS_START_Y + (i%OPTIONS_ROWS_PER_COLUMN)*OPTIONS_ROW_HEIGHT
+}
+
+func (o *OptionsScene) Update() {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		cx, cy := ui.AdjustedCursorPosition()
+		for i, row := range o.Rows {
+			x, y := RowPosition(i)
+			if cx > float64(x) && cx < float64(x+OPTIONS_COLUMN_WIDTH) && cy > float64(y) && cy < float64(y+OPTIONS_ROW_HEIGHT) {
+				row.Selected = (row.Selected + 1) % len(row.Values)
+			}
+		}
+
+		if cy > OPTIONS_BACK_Y-20 && cy < OPTIONS_BACK_Y+20 && cx > CENTER-100 && cx < CENTER+100 {
+			o.SwitchSceneFunc("menu")
+		}
+	}
+}
+
+func (o *OptionsScene) Draw(screen *ui.ScaledScreen) {
+	screen.DrawTextCenteredAt("Options", 48, 480, 40, color.White)
+	for i, row := range o.Rows {
+		x, y := RowPosition(i)
+		screen.DrawText(row.Label, 16, x, y+4, color.White)
+		screen.DrawText(row.Values[row.Selected], 16, x+OPTIONS_VALUE_OFFSET, y+4, color.RGBA{0x6a, 0xbe, 0x30, 0xff})
+	}
+	screen.DrawTextCenteredAt("Back", 24, CENTER, OPTIONS_BACK_Y, color.White)
+}
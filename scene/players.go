@@ -0,0 +1,206 @@
+package scene
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/prizelobby/reverset-raiders/ai"
+	"github.com/prizelobby/reverset-raiders/core"
+	"github.com/prizelobby/reverset-raiders/ui"
+	"github.com/prizelobby/reverset-raiders/ui/animation"
+)
+
+// HumanPlayer is a player who chooses moves by clicking on the game scene
+type HumanPlayer struct {
+	moves chan core.GameMove
+}
+
+func NewHumanPlayer() *HumanPlayer {
+	return &HumanPlayer{moves: make(chan core.GameMove, 1)}
+}
+
+func (h *HumanPlayer) ChooseMove(ctx context.Context, view core.GameView) (core.GameMove, error) {
+	select {
+	case m := <-h.moves:
+		return m, nil
+	case <-ctx.Done():
+		return core.GameMove{}, ctx.Err()
+	}
+}
+
+// Submit passes on the move confirmed in the scene
+func (h *HumanPlayer) Submit(m core.GameMove) {
+	h.moves <- m
+}
+
+type PlayerKind int
+
+const (
+	HUMAN_PLAYER PlayerKind = iota
+	AGENT_PLAYER
+	RANDOM_PLAYER
+	MCTS_PLAYER
+)
+
+// PlayerName describes the player for game records
+func PlayerName(kind PlayerKind, settings GameSettings) string {
+	if kind == AGENT_PLAYER {
+		return "AI (" + settings.Difficulty.String() + ")"
+	} else if kind == RANDOM_PLAYER {
+		return "Random"
+	} else if kind == MCTS_PLAYER {
+		return "MCTS"
+	}
+	return "Human"
+}
+
+func NewPlayer(kind PlayerKind, settings GameSettings, game *core.Game, seed int64) core.Player {
+	if kind == AGENT_PLAYER {
+		agent := ai.NewAgentWithRules(game.Rules, game.Seed, seed)
+		agent.Workers = runtime.NumCPU()
+		agent.Weights = settings.Weights
+		agent.SetDifficulty(settings.Difficulty)
+		// with a clock the expert searches as deep as its time allows
+		if game.Clock != nil && settings.Difficulty == ai.EXPERT {
+			agent.Depth = ai.MAX_SEARCH_DEPTH
+		}
+		return agent
+	} else if kind == RANDOM_PLAYER {
+		return ai.NewRandomPlayer(seed)
+	} else if kind == MCTS_PLAYER {
+		// with a clock the player keeps playing out games until its time is up
+		player := ai.NewMCTSPlayer(seed)
+		player.Weights = settings.Weights
+		if game.Clock != nil && !game.Clock.Control.IsUntimed() {
+			player.Iterations = 0
+		}
+		return player
+	}
+	return NewHumanPlayer()
+}
+
+// PlayerMove is a move chosen by one of the players in the background
+type PlayerMove struct {
+	Side core.Alignment
+	Move core.GameMove
+}
+
+func (g *GameScene) Player(side core.Alignment) core.Player {
+	if side == core.WEST {
+		return g.WestPlayer
+	}
+	return g.EastPlayer
+}
+
+func (g *GameScene) IsHuman(side core.Alignment) bool {
+	_, ok := g.Player(side).(*HumanPlayer)
+	return ok
+}
+
+// StartTurn asks the players that move next for their moves. The moves arrive on MoveChan
+// and are played once every side has chosen
+func (g *GameScene) StartTurn() {
+	sides := []core.Alignment{g.Game.CurrentTurn}
+	if g.Game.Rules.Simultaneous {
+		sides = []core.Alignment{core.EAST, core.WEST}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancelTurn = cancel
+	g.pendingSides = sides
+	g.chosenMoves = make(map[core.Alignment]core.GameMove)
+
+	now := time.Now()
+	for _, side := range sides {
+		side := side
+		player := g.Player(side)
+		view := core.NewGameView(g.Game, side)
+		playerCtx, cancelPlayer := context.WithCancel(ctx)
+		// bots play the best move they have before they run out of time. people get
+		// to use all of theirs
+		if deadline := ai.DeadlineFor(g.Game.Clock, side, now); !deadline.IsZero() && !g.IsHuman(side) {
+			playerCtx, cancelPlayer = context.WithDeadline(ctx, deadline)
+		}
+		go func() {
+			defer cancelPlayer()
+			move, err := player.ChooseMove(playerCtx, view)
+			if err != nil {
+				return
+			}
+			select {
+			case g.MoveChan <- PlayerMove{Side: side, Move: move}:
+			case <-ctx.Done():
+			}
+		}()
+	}
+	g.WaitForNextSide()
+}
+
+// WaitForNextSide switches to waiting for the first side that hasn't chosen its move.
+// The clock only runs for one side at a time, so in the simultaneous variant it runs for
+// each side in turn
+func (g *GameScene) WaitForNextSide() {
+	g.UIState = WAITING_FOR_OPP_MOVE
+	if len(g.pendingSides) == 0 {
+		return
+	}
+	side := g.pendingSides[0]
+	if g.Game.Clock != nil && g.Game.Clock.Running != side {
+		g.Game.Clock.Start(side, time.Now())
+	}
+	if g.IsHuman(side) {
+		g.inputSide = side
+		g.UIState = WAITING_FOR_PLAYER_MOVE
+	}
+}
+
+// SubmitMove hands the move selected with the mouse to the human player of the side.
+// The side is done as soon as it confirms, so that its clock stops right away
+func (g *GameScene) SubmitMove(m core.GameMove) {
+	side := g.inputSide
+	if g.StopClock() {
+		return
+	}
+	g.removePending(side)
+	g.Player(side).(*HumanPlayer).Submit(m)
+	g.WaitForNextSide()
+}
+
+func (g *GameScene) removePending(side core.Alignment) {
+	for i, s := range g.pendingSides {
+		if s == side {
+			g.pendingSides = append(g.pendingSides[:i:i], g.pendingSides[i+1:]...)
+			return
+		}
+	}
+}
+
+// ReceiveMove takes the move of one of the players, and plays the turn once every side
+// has chosen
+func (g *GameScene) ReceiveMove(pm PlayerMove) {
+	if g.Game.Clock != nil && g.Game.Clock.Running == pm.Side && g.StopClock() {
+		return
+	}
+	g.chosenMoves[pm.Side] = pm.Move
+	g.removePending(pm.Side)
+	if len(g.pendingSides) > 0 {
+		g.WaitForNextSide()
+		return
+	}
+
+	g.cancelTurn()
+	if g.Game.Rules.Simultaneous {
+		g.RevealMoves(g.chosenMoves[core.EAST], g.chosenMoves[core.WEST])
+		return
+	}
+	// people have already seen their own move
+	if !g.IsHuman(pm.Side) {
+		tiles := make([]*ui.TileSprite, 0, len(pm.Move.Coords))
+		for _, c := range pm.Move.Coords {
+			tiles = append(tiles, g.TileSprites[c.X][c.Y])
+		}
+		g.OngoingAnimation = animation.NewTileHighlightAnimation(tiles...)
+	}
+	g.EventsToAnimate = g.ApplyMove(pm.Move)
+	g.UIState = WAITING_FOR_PLAYER_ANIMIMATION
+}
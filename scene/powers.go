@@ -0,0 +1,90 @@
+package scene
+
+import (
+	"image/color"
+	"strconv"
+
+	"github.com/prizelobby/reverset-raiders/core"
+	"github.com/prizelobby/reverset-raiders/ui"
+)
+
+const POWER_BUTTON_X_CENTER = 480
+const POWER_BUTTON_Y = 404
+const POWER_BUTTON_WIDTH = 120
+const POWER_BUTTON_HEIGHT = 22
+const POWER_BUTTON_SPACING = 130
+
+func PowerButtonPosition(i int) (int, int) {
+	x := POWER_BUTTON_X_CENTER + (i-len(core.ALL_POWERS)/2)*POWER_BUTTON_SPACING - POWER_BUTTON_WIDTH/2
+	return x, POWER_BUTTON_Y
+}
+
+// PowerButtonAt returns the power whose button is under the cursor, or NO_POWER
+func (g *GameScene) PowerButtonAt(x, y float64) core.Power {
+	if !g.Game.Rules.Powers {
+		return core.NO_POWER
+	}
+	for i, p := range core.ALL_POWERS {
+		bx, by := PowerButtonPosition(i)
+		if x > float64(bx) && x < float64(bx+POWER_BUTTON_WIDTH) && y > float64(by) && y < float64(by+POWER_BUTTON_HEIGHT) {
+			return p
+		}
+	}
+	return core.NO_POWER
+}
+
+// AwaitingPowerTarget tells whether the next click on a tile picks the target of a power
+func (g *GameScene) AwaitingPowerTarget() bool {
+	return g.selectedPower.Power.NeedsTarget() && !g.powerTargeted
+}
+
+func (g *GameScene) SelectPower(p core.Power) {
+	g.ClearPowerTarget()
+	if g.selectedPower.Power == p {
+		g.selectedPower = core.PowerMove{}
+		return
+	}
+	if !g.Game.PowerReady(g.inputSide, p) {
+		return
+	}
+	if !p.NeedsTarget() && !g.Game.CanUsePower(g.inputSide, core.PowerMove{Power: p}) {
+		return
+	}
+	g.selectedPower = core.PowerMove{Power: p}
+}
+
+func (g *GameScene) TargetPower(i, j int) {
+	p := core.PowerMove{Power: g.selectedPower.Power, Target: core.MapCoord{X: i, Y: j}}
+	if !g.Game.CanUsePower(g.inputSide, p) {
+		return
+	}
+	g.selectedPower = p
+	g.powerTargeted = true
+	g.TileSprites[i][j].Marked = true
+}
+
+func (g *GameScene) ClearPowerTarget() {
+	if g.powerTargeted {
+		g.TileSprites[g.selectedPower.Target.X][g.selectedPower.Target.Y].Marked = false
+	}
+	g.powerTargeted = false
+}
+
+func (g *GameScene) DrawPowers(screen *ui.ScaledScreen) {
+	if !g.Game.Rules.Powers {
+		return
+	}
+	for i, p := range core.ALL_POWERS {
+		x, y := PowerButtonPosition(i)
+		var background color.Color = color.RGBA{0x30, 0x30, 0x40, 0xff}
+		text := p.String()
+		if g.selectedPower.Power == p {
+			background = color.RGBA{0x6a, 0xbe, 0x30, 0xff}
+		} else if !g.Game.PowerReady(g.inputSide, p) {
+			background = color.RGBA{0x18, 0x18, 0x20, 0xff}
+			text += " (" + strconv.Itoa(g.Game.TurnsUntilReady(g.inputSide, p)) + ")"
+		}
+		screen.DrawRect(float64(x), float64(y), POWER_BUTTON_WIDTH, POWER_BUTTON_HEIGHT, background)
+		screen.DrawTextCenteredAt(text, 14, x+POWER_BUTTON_WIDTH/2, y+POWER_BUTTON_HEIGHT/2, color.White)
+	}
+}
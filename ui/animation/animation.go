@@ -18,6 +18,55 @@ type Anim interface {
 	IsFinished() bool
 }
 
+type CreatureSpriteShield struct {
+	Finished       bool
+	CreatureSprite *ui.CreatureSprite
+	Shielded       bool
+}
+
+func NewCreatureSpriteShield(cs *ui.CreatureSprite, shielded bool) *CreatureSpriteShield {
+	return &CreatureSpriteShield{
+		CreatureSprite: cs,
+		Shielded:       shielded,
+	}
+}
+
+func (c *CreatureSpriteShield) Update() {
+	c.CreatureSprite.Shielded = c.Shielded
+	c.Finished = true
+}
+
+func (c *CreatureSpriteShield) IsFinished() bool {
+	return c.Finished
+}
+
+type CreatureSpriteLevelUp struct {
+	CurrentFrame   int
+	CreatureSprite *ui.CreatureSprite
+	Level          int
+}
+
+func NewCreatureSpriteLevelUp(cs *ui.CreatureSprite, level int) *CreatureSpriteLevelUp {
+	return &CreatureSpriteLevelUp{
+		CreatureSprite: cs,
+		Level:          level,
+	}
+}
+
+// the creature flashes before its new level shows up
+func (c *CreatureSpriteLevelUp) Update() {
+	c.CurrentFrame += 1
+	c.CreatureSprite.Transp = 0.5 + 0.5*float32((c.CurrentFrame/5)%2)
+	if c.CurrentFrame >= 30 {
+		c.CreatureSprite.Transp = 1.0
+		c.CreatureSprite.Level = c.Level
+	}
+}
+
+func (c *CreatureSpriteLevelUp) IsFinished() bool {
+	return c.CurrentFrame >= 30
+}
+
 type CreatureSpriteUpdatePower struct {
 	Finished       bool
 	CreatureSprite *ui.CreatureSprite
@@ -43,6 +92,30 @@ func (c *CreatureSpriteUpdatePower) IsFinished() bool {
 	return c.Finished
 }
 
+type CreatureSpriteUpdateArmor struct {
+	Finished       bool
+	CreatureSprite *ui.CreatureSprite
+	ArmorDelta     int
+}
+
+func NewCreatureSpriteUpdateArmor(cs *ui.CreatureSprite, v int) *CreatureSpriteUpdateArmor {
+	return &CreatureSpriteUpdateArmor{
+		CreatureSprite: cs,
+		ArmorDelta:     v,
+	}
+}
+
+func (c *CreatureSpriteUpdateArmor) Update() {
+	if !c.Finished {
+		c.CreatureSprite.Armor += c.ArmorDelta
+		c.Finished = true
+	}
+}
+
+func (c *CreatureSpriteUpdateArmor) IsFinished() bool {
+	return c.Finished
+}
+
 type SpriteMovement struct {
 	CurrentPathIndex int
 	Path             []Loc
@@ -196,30 +269,20 @@ func (s *SplatAnimation) IsFinished() bool {
 
 type TileHighlightAnimation struct {
 	CurrentFrame int
-	TileSprite1  *ui.TileSprite
-	TileSprite2  *ui.TileSprite
+	TileSprites  []*ui.TileSprite
 }
 
-func NewTileHighlightAnimation(t1, t2 *ui.TileSprite) *TileHighlightAnimation {
+func NewTileHighlightAnimation(tiles ...*ui.TileSprite) *TileHighlightAnimation {
 	return &TileHighlightAnimation{
 		CurrentFrame: 0,
-		TileSprite1:  t1,
-		TileSprite2:  t2,
+		TileSprites:  tiles,
 	}
 }
 
 func (t *TileHighlightAnimation) Update() {
 	t.CurrentFrame += 1
-	t.TileSprite1.Selected = true
-	if t.TileSprite2 != nil {
-		t.TileSprite2.Selected = true
-	}
-
-	if t.CurrentFrame >= 45 {
-		t.TileSprite1.Selected = false
-		if t.TileSprite2 != nil {
-			t.TileSprite2.Selected = false
-		}
+	for _, ts := range t.TileSprites {
+		ts.Selected = t.CurrentFrame < 45
 	}
 }
 
@@ -246,3 +309,76 @@ func (e *EffectSpriteAnimation) IsFinished() bool {
 	// the animation is complete
 	return e.CurrentFrame > 15
 }
+
+type BannerAnimation struct {
+	CurrentFrame int
+	Banner       *ui.BannerSprite
+}
+
+func NewBannerAnimation(b *ui.BannerSprite) *BannerAnimation {
+	return &BannerAnimation{CurrentFrame: 0, Banner: b}
+}
+
+func (b *BannerAnimation) Update() {
+	b.CurrentFrame += 1
+	// hold the banner for a second before fading it out
+	if b.CurrentFrame > 60 {
+		b.Banner.Transp = 1.0 - (float32(b.CurrentFrame-60) / 30)
+	}
+	if b.CurrentFrame >= 90 {
+		b.Banner.Removed = true
+	}
+}
+
+func (b *BannerAnimation) IsFinished() bool {
+	return b.CurrentFrame >= 90
+}
+
+type TileChangeAnimation struct {
+	CurrentFrame int
+	TileSprites  []*ui.TileSprite
+}
+
+func NewTileChangeAnimation(tiles ...*ui.TileSprite) *TileChangeAnimation {
+	return &TileChangeAnimation{
+		CurrentFrame: 0,
+		TileSprites:  tiles,
+	}
+}
+
+func (t *TileChangeAnimation) Update() {
+	t.CurrentFrame += 1
+	for _, ts := range t.TileSprites {
+		ts.Changed = t.CurrentFrame < 90
+	}
+}
+
+func (t *TileChangeAnimation) IsFinished() bool {
+	return t.CurrentFrame >= 90
+}
+
+// GroupAnimation plays several animations at the same time
+type GroupAnimation struct {
+	Anims []Anim
+}
+
+func NewGroupAnimation(anims ...Anim) *GroupAnimation {
+	return &GroupAnimation{Anims: anims}
+}
+
+func (g *GroupAnimation) Update() {
+	for _, a := range g.Anims {
+		if !a.IsFinished() {
+			a.Update()
+		}
+	}
+}
+
+func (g *GroupAnimation) IsFinished() bool {
+	for _, a := range g.Anims {
+		if !a.IsFinished() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"image/color"
+)
+
+// BannerSprite is a line of text shown across the middle of the screen to announce
+// something that happened in the game
+type BannerSprite struct {
+	Text    string
+	Transp  float32
+	Removed bool
+}
+
+func NewBannerSprite(text string) *BannerSprite {
+	return &BannerSprite{
+		Text:    text,
+		Transp:  1.0,
+		Removed: false,
+	}
+}
+
+func (b *BannerSprite) Draw(screen *ScaledScreen) {
+	if b.Removed {
+		return
+	}
+
+	screen.DrawRect(0, 200, 960, 80, color.NRGBA{0, 0, 0, uint8(200 * b.Transp)})
+	screen.DrawTextCenteredAt(b.Text, 40, 480, 240, color.NRGBA{0xff, 0xff, 0xff, uint8(255 * b.Transp)})
+}
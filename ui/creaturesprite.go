@@ -10,14 +10,17 @@ import (
 )
 
 type CreatureSprite struct {
-	X       int
-	Y       int
-	Facing  core.Alignment
-	Img     *ebiten.Image
-	Power   int
-	Rot     int
-	Transp  float32
-	Removed bool
+	X        int
+	Y        int
+	Facing   core.Alignment
+	Img      *ebiten.Image
+	Power    int
+	Rot      int
+	Transp   float32
+	Removed  bool
+	Shielded bool
+	Armor    int
+	Level    int
 }
 
 func (c *CreatureSprite) MoveTo(x, y int) {
@@ -47,13 +50,16 @@ func NewCreatureSprite(x, y int, c *core.Creature) *CreatureSprite {
 	}
 
 	return &CreatureSprite{
-		X:       x,
-		Y:       y,
-		Facing:  c.Alignment,
-		Img:     img,
-		Power:   c.Power,
-		Transp:  1.0,
-		Removed: false,
+		X:        x,
+		Y:        y,
+		Facing:   c.Alignment,
+		Img:      img,
+		Power:    c.Power,
+		Shielded: c.Shielded,
+		Armor:    c.Armor,
+		Level:    c.Level(),
+		Transp:   1.0,
+		Removed:  false,
 	}
 }
 
@@ -77,4 +83,15 @@ func (c *CreatureSprite) Draw(screen *ScaledScreen) {
 	opts.GeoM.Translate(float64(c.X), float64(c.Y))
 	screen.DrawImage(c.Img, opts)
 	screen.DrawTextCenteredAt(strconv.Itoa(c.Power), 10, c.X+32, c.Y+35, color.White)
+	// a pip for every level
+	for i := 0; i < c.Level; i++ {
+		screen.DrawRect(float64(c.X+14+i*6), float64(c.Y+4), 4, 4, color.RGBA{0xfb, 0xf2, 0x36, 0xff})
+	}
+	if c.Armor > 0 {
+		screen.DrawRect(float64(c.X+6), float64(c.Y+29), 14, 12, color.RGBA{0x8a, 0x8f, 0x98, 0xff})
+		screen.DrawTextCenteredAt(strconv.Itoa(c.Armor), 8, c.X+13, c.Y+35, color.White)
+	}
+	if c.Shielded {
+		screen.DrawRect(float64(c.X+44), float64(c.Y+30), 8, 10, color.RGBA{0x5f, 0xcd, 0xe4, 0xff})
+	}
 }
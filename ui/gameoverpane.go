@@ -8,6 +8,7 @@ import (
 
 type GameOverPane struct {
 	Winner core.Alignment
+	Result core.GameResult
 }
 
 func (g *GameOverPane) Draw(screen *ScaledScreen) {
@@ -16,7 +17,10 @@ func (g *GameOverPane) Draw(screen *ScaledScreen) {
 	winner := "You win!"
 	if g.Winner == core.WEST {
 		winner = "You lose"
+	} else if g.Winner == 0 {
+		winner = "Draw"
 	}
 	screen.DrawTextCenteredAt(winner, 32.0, 480, 300, color.White)
+	screen.DrawTextCenteredAt(g.Result.String(), 24.0, 480, 340, color.White)
 	screen.DrawTextCenteredAt("Return to main", 24.0, 480, 400, color.White)
 }
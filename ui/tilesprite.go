@@ -14,6 +14,13 @@ type TileSprite struct {
 	Tile     *core.Tile
 	Selected bool
 	Reversed bool
+	// marks reversed tiles with the color of the side that reversed them
+	ShowOwner bool
+	// highlights the effects after a world event changed them
+	Changed bool
+	// the target of the power the player is about to use
+	Marked bool
+	Frozen bool
 }
 
 func NewTileSprite(x, y int, tile *core.Tile) *TileSprite {
@@ -53,15 +60,34 @@ func (t *TileSprite) Draw(screen *ScaledScreen) {
 	} else {
 		rvColor = color.RGBA{180, 180, 180, 255}
 	}
+	if t.Changed {
+		obColor = color.RGBA{0xfb, 0xf2, 0x36, 0xff}
+		rvColor = obColor
+	}
 
 	screen.DrawImage(img, opts)
 	offset := 20
 	fontSize := 12.0
-	if t.Tile.HasCreature {
+	if t.Tile.HasCreature() {
 		offset = 35
 		fontSize = 10.0
 	}
 
+	if t.ShowOwner && t.Tile.Reversed && t.Tile.ReversedBy != 0 {
+		var ownerColor color.Color = color.RGBA{0x6a, 0xbe, 0x30, 0xff}
+		if t.Tile.ReversedBy == core.WEST {
+			ownerColor = color.RGBA{0xac, 0x32, 0x32, 0xff}
+		}
+		screen.DrawRect(float64(left+61), float64(top+46), 8, 8, ownerColor)
+	}
+
+	if t.Frozen {
+		screen.DrawRect(float64(left+45), float64(top+46), 40, 8, color.RGBA{0x5f, 0xcd, 0xe4, 0xff})
+	}
+	if t.Marked {
+		screen.DrawRect(float64(left+61), float64(top+10), 8, 8, color.RGBA{0xfb, 0xf2, 0x36, 0xff})
+	}
+
 	screen.DrawTextCenteredAt(t.Tile.ObverseEffect.String(), fontSize, left+65, top+50-offset, obColor)
 	screen.DrawTextCenteredAt(t.Tile.ReverseEffect.String(), fontSize, left+65, top+50+offset, rvColor)
 }